@@ -0,0 +1,35 @@
+import crypto from 'node:crypto';
+
+/**
+ * Identity propagation to the Go domain service (ADR-001).
+ * Go only trusts these headers when the HMAC matches its shared secret,
+ * see go-domain/internal/middleware/identity.go for the verifying side.
+ */
+export const IDENTITY_HEADERS = [
+  'x-identity-user-id',
+  'x-identity-roles',
+  'x-identity-tenant',
+  'x-identity-timestamp',
+  'x-identity-signature',
+];
+
+/**
+ * Builds signed identity headers for an authenticated caller.
+ * @param {{ userId: string, roles?: string[], tenantId?: string }} identity
+ * @param {string} secret shared with Go as IDENTITY_SHARED_SECRET
+ */
+export function signIdentity(identity, secret) {
+  const timestamp = String(Math.floor(Date.now() / 1000));
+  const roles = (identity.roles || []).join(',');
+  const tenant = identity.tenantId || '';
+  const payload = ['v1', timestamp, identity.userId, roles, tenant].join('\n');
+  const signature = crypto.createHmac('sha256', secret).update(payload).digest('hex');
+
+  return {
+    'x-identity-user-id': identity.userId,
+    'x-identity-roles': roles,
+    'x-identity-tenant': tenant,
+    'x-identity-timestamp': timestamp,
+    'x-identity-signature': signature,
+  };
+}
@@ -1,12 +1,21 @@
 import express from 'express';
+import { authRouter } from './routes/auth.js';
 import { usersRouter } from './routes/users.js';
 import { healthRouter } from './routes/health.js';
+import { sessionMiddleware } from './session.js';
 
 const app = express();
 const PORT = process.env.PORT || 3000;
 const GO_API_URL = process.env.GO_API_URL || 'http://localhost:8080';
+const IDENTITY_SHARED_SECRET = process.env.IDENTITY_SHARED_SECRET || '';
+// Signs session cookies; known only to the BFF
+const SESSION_SECRET = process.env.SESSION_SECRET || '';
+// Proxies (e.g. the frontend's nginx) whose X-Forwarded-For sets req.ip; none by default
+const TRUST_PROXY = process.env.TRUST_PROXY || false;
 
+app.set('trust proxy', TRUST_PROXY);
 app.use(express.json());
+app.use(sessionMiddleware(SESSION_SECRET));
 
 // Liveness probe - early check
 app.get('/ping', (req, res) => {
@@ -14,10 +23,17 @@ app.get('/ping', (req, res) => {
 });
 
 // Routers
-app.use('/api/v1/users', usersRouter(GO_API_URL));
+app.use('/api/v1/auth', authRouter(GO_API_URL, SESSION_SECRET));
+app.use('/api/v1/users', usersRouter(GO_API_URL, IDENTITY_SHARED_SECRET));
 app.use('/health', healthRouter(GO_API_URL));
 
 app.listen(PORT, () => {
   console.log(`BFF listening on http://localhost:${PORT}`);
   console.log(`Proxying to Go API at ${GO_API_URL}`);
+  if (!IDENTITY_SHARED_SECRET) {
+    console.warn('IDENTITY_SHARED_SECRET not set, requests are forwarded without caller identity');
+  }
+  if (!SESSION_SECRET) {
+    console.warn('SESSION_SECRET not set, sign-in is disabled');
+  }
 });
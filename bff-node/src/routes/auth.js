@@ -0,0 +1,95 @@
+import { Router } from 'express';
+import { clearSession, setSession } from '../session.js';
+
+// Signed-in sessions last a working day; a password-only session waiting for
+// its second factor only a few minutes
+const SESSION_TTL_MS = 8 * 60 * 60 * 1000;
+const MFA_PENDING_TTL_MS = 5 * 60 * 1000;
+
+/**
+ * Auth router - signs browsers in and out (ADR-001: authentication is a BFF concern).
+ * Credentials and second factors are checked by the Go domain service; on
+ * success the BFF starts a session, which sessionMiddleware turns into the
+ * req.identity forwarded to Go.
+ */
+export function authRouter(goApiUrl, sessionSecret) {
+  const router = Router();
+  const baseUrl = goApiUrl.replace(/\/$/, '');
+
+  const callGo = (req, path, body) => fetch(`${baseUrl}${path}`, {
+    method: 'POST',
+    headers: { 'content-type': 'application/json', 'x-forwarded-for': req.ip },
+    body: JSON.stringify(body),
+  });
+
+  // Passes a Go error (problem+json, Retry-After on lockouts) to the browser
+  const relay = async (res, upstream) => {
+    res.status(upstream.status);
+    for (const name of ['content-type', 'retry-after']) {
+      const value = upstream.headers.get(name);
+      if (value) res.setHeader(name, value);
+    }
+    res.send(await upstream.text());
+  };
+
+  router.use((req, res, next) => {
+    if (!sessionSecret) return res.status(503).json({ error: 'Sign-in is not configured' });
+    next();
+  });
+
+  // POST /api/v1/auth/login { login, password }
+  router.post('/login', async (req, res) => {
+    try {
+      const upstream = await callGo(req, '/api/v1/users/authenticate', {
+        login: req.body?.login,
+        password: req.body?.password,
+      });
+      if (!upstream.ok) return relay(res, upstream);
+
+      const { mfa_required, mfa_challenge, user } = await upstream.json();
+      if (mfa_required) {
+        // The challenge stays in the httpOnly cookie; only /mfa redeems it
+        setSession(req, res, { mfaChallenge: mfa_challenge }, sessionSecret, MFA_PENDING_TTL_MS);
+        return res.json({ mfa_required: true });
+      }
+      setSession(req, res, { userId: user.id }, sessionSecret, SESSION_TTL_MS);
+      res.json({ mfa_required: false, user });
+    } catch (err) {
+      res.status(502).json({ error: 'Upstream unavailable' });
+    }
+  });
+
+  // POST /api/v1/auth/mfa { code } - TOTP or recovery code after /login
+  router.post('/mfa', async (req, res) => {
+    if (!req.session?.mfaChallenge) {
+      return res.status(401).json({ error: 'Sign in with your password first' });
+    }
+    try {
+      const upstream = await callGo(req, '/api/v1/users/mfa/verify', {
+        challenge: req.session.mfaChallenge,
+        code: req.body?.code,
+      });
+      if (!upstream.ok) return relay(res, upstream);
+
+      const result = await upstream.json();
+      setSession(req, res, { userId: result.user.id }, sessionSecret, SESSION_TTL_MS);
+      res.json(result);
+    } catch (err) {
+      res.status(502).json({ error: 'Upstream unavailable' });
+    }
+  });
+
+  // POST /api/v1/auth/logout
+  router.post('/logout', (req, res) => {
+    clearSession(req, res);
+    res.status(204).end();
+  });
+
+  // GET /api/v1/auth/me - the signed-in user's id
+  router.get('/me', (req, res) => {
+    if (!req.identity) return res.status(401).json({ error: 'Not signed in' });
+    res.json({ user_id: req.identity.userId });
+  });
+
+  return router;
+}
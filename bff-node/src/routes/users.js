@@ -1,20 +1,36 @@
 import { Router } from 'express';
+import { IDENTITY_HEADERS, signIdentity } from '../identity.js';
 
 /**
  * Users router - proxies /api/v1/users/* to Go domain service.
  * Per ADR-001: BFF routes to Go, does not implement business logic.
  * Uses fetch-based forward to avoid 301 redirect loops from http-proxy-middleware.
+ * Identity headers from the browser are dropped; the caller set on req.identity
+ * by authentication middleware is forwarded signed with identitySecret.
+ * X-Forwarded-For is replaced by req.ip, so browsers cannot pick the client
+ * IP that Go throttles logins by.
  */
-export function usersRouter(goApiUrl) {
+export function usersRouter(goApiUrl, identitySecret) {
   const router = Router();
 
+  // Second factors are only submitted by the BFF's own sign-in, never proxied
+  // for anonymous callers
+  router.post('/mfa/verify', (req, res) => {
+    res.status(404).json({ error: 'Not found' });
+  });
+
   router.use('/', async (req, res) => {
     const url = `${goApiUrl.replace(/\/$/, '')}${req.originalUrl}`;
 
     try {
       const headers = {};
       for (const [k, v] of Object.entries(req.headers)) {
-        if (k.toLowerCase() !== 'host' && v) headers[k] = v;
+        const name = k.toLowerCase();
+        if (name !== 'host' && name !== 'x-forwarded-for' && !IDENTITY_HEADERS.includes(name) && v) headers[k] = v;
+      }
+      if (req.ip) headers['x-forwarded-for'] = req.ip;
+      if (req.identity && identitySecret) {
+        Object.assign(headers, signIdentity(req.identity, identitySecret));
       }
       const body = req.method !== 'GET' && req.method !== 'HEAD' && req.body
         ? JSON.stringify(req.body) : undefined;
@@ -0,0 +1,82 @@
+import crypto from 'node:crypto';
+
+/**
+ * Browser sessions (ADR-001: authentication lives in the BFF).
+ * The session is a cookie holding the user id (or, between password and
+ * second factor, the Go MFA challenge) and expiry, signed with HMAC-SHA256 so
+ * the BFF stays stateless. Go never sees the cookie, only the
+ * signed identity headers derived from it.
+ */
+export const SESSION_COOKIE = 'sid';
+
+function sign(value, secret) {
+  return crypto.createHmac('sha256', secret).update(value).digest('base64url');
+}
+
+/**
+ * Serializes a session as `<base64url JSON>.<signature>`.
+ * @param {{ userId?: string, mfaChallenge?: string, expiresAt: number }} session
+ */
+export function encodeSession(session, secret) {
+  const value = Buffer.from(JSON.stringify(session)).toString('base64url');
+  return `${value}.${sign(value, secret)}`;
+}
+
+/**
+ * Returns the session in token, or null if it is malformed, forged or expired.
+ */
+export function decodeSession(token, secret, now = Date.now()) {
+  const [value, signature, extra] = (token || '').split('.');
+  if (!value || !signature || extra !== undefined) return null;
+
+  const expected = Buffer.from(sign(value, secret));
+  const actual = Buffer.from(signature);
+  if (expected.length !== actual.length || !crypto.timingSafeEqual(expected, actual)) return null;
+
+  try {
+    const session = JSON.parse(Buffer.from(value, 'base64url').toString());
+    if (typeof session.userId !== 'string' && typeof session.mfaChallenge !== 'string') return null;
+    if (!(session.expiresAt > now)) return null;
+    return session;
+  } catch {
+    return null;
+  }
+}
+
+function readCookie(header, name) {
+  for (const part of (header || '').split(';')) {
+    const [key, ...rest] = part.trim().split('=');
+    if (key === name) return decodeURIComponent(rest.join('='));
+  }
+  return undefined;
+}
+
+/**
+ * Sets req.session from the session cookie, and req.identity (forwarded to Go
+ * by the routers) once the sign-in is complete, including any second factor.
+ * Without a secret no session is ever accepted.
+ */
+export function sessionMiddleware(secret) {
+  return (req, res, next) => {
+    req.session = secret ? decodeSession(readCookie(req.headers.cookie, SESSION_COOKIE), secret) : null;
+    if (typeof req.session?.userId === 'string') {
+      req.identity = { userId: req.session.userId };
+    }
+    next();
+  };
+}
+
+function cookieOptions(req) {
+  return { httpOnly: true, sameSite: 'strict', secure: req.secure, path: '/' };
+}
+
+/** Starts or replaces the caller's session, valid for ttlMs */
+export function setSession(req, res, session, secret, ttlMs) {
+  const token = encodeSession({ ...session, expiresAt: Date.now() + ttlMs }, secret);
+  res.cookie(SESSION_COOKIE, token, { ...cookieOptions(req), maxAge: ttlMs });
+}
+
+/** Ends the caller's session in this browser */
+export function clearSession(req, res) {
+  res.clearCookie(SESSION_COOKIE, cookieOptions(req));
+}
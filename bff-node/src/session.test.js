@@ -0,0 +1,51 @@
+import assert from 'node:assert/strict';
+import { test } from 'node:test';
+import { decodeSession, encodeSession, sessionMiddleware, SESSION_COOKIE } from './session.js';
+
+const secret = 'session-secret';
+const now = 1700000000000;
+const session = { userId: '7c9e6679-7425-40de-944b-e07fc1f90ae7', expiresAt: now + 1000 };
+
+test('decodeSession returns a session it signed', () => {
+  assert.deepEqual(decodeSession(encodeSession(session, secret), secret, now), session);
+});
+
+test('decodeSession rejects forged, expired and malformed tokens', () => {
+  const token = encodeSession(session, secret);
+  const [value, signature] = token.split('.');
+  const forged = Buffer.from(JSON.stringify({ ...session, userId: 'someone-else' })).toString('base64url');
+
+  const cases = {
+    'wrong secret': [encodeSession(session, 'other-secret'), now],
+    'altered payload': [`${forged}.${signature}`, now],
+    'truncated signature': [`${value}.${signature.slice(1)}`, now],
+    'extra segment': [`${token}.x`, now],
+    'at expiry': [token, session.expiresAt],
+    'after expiry': [token, session.expiresAt + 1],
+    'no subject': [encodeSession({ expiresAt: now + 1000 }, secret), now],
+    'empty': ['', now],
+    'missing': [undefined, now],
+  };
+  for (const [name, [candidate, at]] of Object.entries(cases)) {
+    assert.equal(decodeSession(candidate, secret, at), null, name);
+  }
+});
+
+test('sessionMiddleware sets an identity only once sign-in is complete', () => {
+  const run = (value, middlewareSecret = secret) => {
+    const req = { headers: { cookie: `theme=dark; ${SESSION_COOKIE}=${encodeURIComponent(value)}` } };
+    sessionMiddleware(middlewareSecret)(req, {}, () => {});
+    return req;
+  };
+  const expiresAt = Date.now() + 60000;
+
+  assert.deepEqual(run(encodeSession({ userId: session.userId, expiresAt }, secret)).identity, { userId: session.userId });
+
+  const pending = run(encodeSession({ mfaChallenge: 'challenge', expiresAt }, secret));
+  assert.equal(pending.session.mfaChallenge, 'challenge');
+  assert.equal(pending.identity, undefined);
+
+  const unconfigured = run(encodeSession({ userId: session.userId, expiresAt }, secret), '');
+  assert.equal(unconfigured.session, null);
+  assert.equal(unconfigured.identity, undefined);
+});
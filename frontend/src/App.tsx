@@ -4,6 +4,7 @@ import { HomePage } from './pages/HomePage'
 import { UsersPage } from './pages/UsersPage'
 import { UserDetailPage } from './pages/UserDetailPage'
 import { HealthPage } from './pages/HealthPage'
+import { LoginPage } from './pages/LoginPage'
 
 function App() {
   return (
@@ -13,6 +14,7 @@ function App() {
         <Route path="/users" element={<UsersPage />} />
         <Route path="/users/:id" element={<UserDetailPage />} />
         <Route path="/health" element={<HealthPage />} />
+        <Route path="/login" element={<LoginPage />} />
       </Routes>
     </Layout>
   )
@@ -0,0 +1,22 @@
+import { api } from './client'
+import type { User } from './users'
+
+export type LoginResponse =
+  | { mfa_required: true }
+  | { mfa_required: false; user: User }
+
+export interface MFAVerification {
+  method: 'totp' | 'recovery_code'
+  recovery_codes_remaining?: number
+  user: User
+}
+
+/** Session endpoints of the BFF; the session itself is an HttpOnly cookie. */
+export const authApi = {
+  login: (login: string, password: string) =>
+    api.post<LoginResponse>('/api/v1/auth/login', { login, password }),
+  verifyMFA: (code: string) =>
+    api.post<MFAVerification>('/api/v1/auth/mfa', { code }),
+  logout: () => api.post<void>('/api/v1/auth/logout', {}),
+  me: () => api.get<{ user_id: string }>('/api/v1/auth/me'),
+}
@@ -5,6 +5,29 @@
 
 const BASE_URL = import.meta.env.VITE_API_URL || ''
 
+/** RFC 7807 problem details returned by the Go domain for every error. */
+export interface Problem {
+  type: string
+  title: string
+  status: number
+  detail?: string
+  instance?: string
+  request_id?: string
+  errors?: { field: string; rule: string; message: string }[]
+}
+
+export class ApiError extends Error {
+  readonly status: number
+  readonly problem?: Problem
+
+  constructor(status: number, message: string, problem?: Problem) {
+    super(message)
+    this.name = 'ApiError'
+    this.status = status
+    this.problem = problem
+  }
+}
+
 async function request<T>(
   path: string,
   options: RequestInit = {}
@@ -18,8 +41,13 @@ async function request<T>(
   const res = await fetch(url, { ...options, headers })
 
   if (!res.ok) {
-    const err = await res.json().catch(() => ({}))
-    throw new Error((err as { error?: string }).error || res.statusText)
+    const body = await res.json().catch(() => ({}))
+    const problem = res.headers.get('Content-Type')?.includes('application/problem+json')
+      ? (body as Problem)
+      : undefined
+    const message =
+      problem?.detail || problem?.title || (body as { error?: string }).error || res.statusText
+    throw new ApiError(res.status, message, problem)
   }
 
   if (res.status === 204) return undefined as T
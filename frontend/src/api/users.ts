@@ -1,3 +1,5 @@
+export type UserStatus = 'active' | 'suspended' | 'deleted'
+
 export interface User {
   id: string
   username: string
@@ -5,13 +7,38 @@ export interface User {
   first_name?: string
   last_name?: string
   is_active: boolean
+  status: UserStatus
   created_at: string
   updated_at: string
+  deleted_at?: string
+  email_verified_at?: string
+  mfa_enabled: boolean
 }
 
 export interface UserListResponse {
   data: User[]
-  pagination: { limit: number; offset: number; total: number }
+  pagination: {
+    limit: number
+    offset?: number // offset mode only
+    total: number // users matching the filters
+    sort: string
+    next_cursor?: string
+    prev_cursor?: string
+  }
+}
+
+export interface UserSearchResult extends User {
+  rank: number
+  match: 'text' | 'similar' // similar: found by trigram similarity (typos)
+  highlights?: Partial<Record<'username' | 'email' | 'first_name' | 'last_name', string>> // HTML-escaped, matches in <mark>
+}
+
+export interface UserSearchResponse {
+  data: UserSearchResult[]
+  pagination: {
+    limit: number
+    offset: number
+  }
 }
 
 export interface CreateUserPayload {
@@ -35,10 +62,14 @@ import { api } from './client'
 export const usersApi = {
   list: (limit = 10, offset = 0) =>
     api.get<UserListResponse>(`/api/v1/users?limit=${limit}&offset=${offset}`),
+  search: (q: string, limit = 10, offset = 0) =>
+    api.get<UserSearchResponse>(`/api/v1/users/search?q=${encodeURIComponent(q)}&limit=${limit}&offset=${offset}`),
   get: (id: string) => api.get<User>(`/api/v1/users/${id}`),
   create: (payload: CreateUserPayload) =>
     api.post<User>('/api/v1/users', payload),
   update: (id: string, payload: UpdateUserPayload) =>
     api.patch<User>(`/api/v1/users/${id}`, payload),
   delete: (id: string) => api.delete(`/api/v1/users/${id}`),
+  suspend: (id: string) => api.post<User>(`/api/v1/users/${id}/suspend`, {}),
+  restore: (id: string) => api.post<User>(`/api/v1/users/${id}/restore`, {}),
 }
@@ -1,5 +1,6 @@
-import { ReactNode } from 'react'
-import { Link, useLocation } from 'react-router-dom'
+import { ReactNode, useEffect, useState } from 'react'
+import { Link, useLocation, useNavigate } from 'react-router-dom'
+import { authApi } from '../api/auth'
 
 interface LayoutProps {
   children: ReactNode
@@ -7,6 +8,19 @@ interface LayoutProps {
 
 export function Layout({ children }: LayoutProps) {
   const location = useLocation()
+  const navigate = useNavigate()
+  const [signedIn, setSignedIn] = useState(false)
+
+  // Re-check on navigation so the link follows sign in and out
+  useEffect(() => {
+    authApi.me().then(() => setSignedIn(true), () => setSignedIn(false))
+  }, [location.pathname])
+
+  const handleLogout = async () => {
+    await authApi.logout()
+    setSignedIn(false)
+    navigate('/login')
+  }
 
   const navLinks = [
     { path: '/', label: 'Home' },
@@ -36,6 +50,13 @@ export function Layout({ children }: LayoutProps) {
             {label}
           </Link>
         ))}
+        <span style={{ marginLeft: 'auto' }}>
+          {signedIn ? (
+            <button onClick={handleLogout}>Sign out</button>
+          ) : (
+            <Link to="/login" style={{ color: '#64748b', textDecoration: 'none' }}>Sign in</Link>
+          )}
+        </span>
       </nav>
       <main>{children}</main>
     </div>
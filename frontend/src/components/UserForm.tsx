@@ -1,4 +1,5 @@
 import { useState } from 'react'
+import { ApiError } from '../api/client'
 import type { CreateUserPayload, UpdateUserPayload, User } from '../api/users'
 
 interface UserFormProps {
@@ -35,7 +36,12 @@ export function UserForm({ user, copyFrom, onSave, onCancel }: UserFormProps) {
         await onSave({ username, email, password, first_name: firstName || undefined, last_name: lastName || undefined })
       }
     } catch (err) {
-      setError(err instanceof Error ? err.message : 'Failed to save')
+      const fieldErrors = err instanceof ApiError ? err.problem?.errors : undefined
+      if (fieldErrors?.length) {
+        setError(fieldErrors.map((f) => `${f.field} ${f.message}`).join('; '))
+      } else {
+        setError(err instanceof Error ? err.message : 'Failed to save')
+      }
     } finally {
       setSaving(false)
     }
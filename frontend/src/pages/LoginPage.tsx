@@ -0,0 +1,89 @@
+import { useState } from 'react'
+import { useNavigate } from 'react-router-dom'
+import { authApi } from '../api/auth'
+
+export function LoginPage() {
+  const navigate = useNavigate()
+  const [login, setLogin] = useState('')
+  const [password, setPassword] = useState('')
+  const [code, setCode] = useState('')
+  const [mfaRequired, setMfaRequired] = useState(false)
+  const [submitting, setSubmitting] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError(null)
+    setSubmitting(true)
+    try {
+      if (mfaRequired) {
+        await authApi.verifyMFA(code)
+      } else {
+        const res = await authApi.login(login, password)
+        if (res.mfa_required) {
+          setMfaRequired(true)
+          return
+        }
+      }
+      navigate('/users')
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to sign in')
+    } finally {
+      setSubmitting(false)
+    }
+  }
+
+  const inputStyle = { width: '100%', padding: '0.5rem', marginBottom: '0.75rem', borderRadius: '4px', border: '1px solid #e2e8f0' }
+  const labelStyle = { display: 'block', marginBottom: '0.25rem', fontSize: '0.875rem', color: '#64748b' }
+
+  return (
+    <div>
+      <h1 style={{ marginBottom: '1rem' }}>Sign in</h1>
+      <form onSubmit={handleSubmit} style={{ maxWidth: '400px' }}>
+        {error && <p style={{ color: '#dc2626', marginBottom: '1rem' }}>{error}</p>}
+        {mfaRequired ? (
+          <div>
+            <label style={labelStyle}>Authenticator or recovery code</label>
+            <input
+              type="text"
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
+              required
+              autoComplete="one-time-code"
+              autoFocus
+              style={inputStyle}
+            />
+          </div>
+        ) : (
+          <>
+            <div>
+              <label style={labelStyle}>Username or email</label>
+              <input
+                type="text"
+                value={login}
+                onChange={(e) => setLogin(e.target.value)}
+                required
+                autoComplete="username"
+                style={inputStyle}
+              />
+            </div>
+            <div>
+              <label style={labelStyle}>Password</label>
+              <input
+                type="password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                required
+                autoComplete="current-password"
+                style={inputStyle}
+              />
+            </div>
+          </>
+        )}
+        <button type="submit" disabled={submitting} style={{ marginTop: '1rem' }}>
+          {submitting ? 'Signing in...' : mfaRequired ? 'Verify' : 'Sign in'}
+        </button>
+      </form>
+    </div>
+  )
+}
@@ -5,7 +5,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/audit"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/idempotency"
 	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
 	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/users"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,6 +21,12 @@ import (
 func (app *application) mount() http.Handler {
 	r := gin.Default()
 
+	// Client IPs come from X-Forwarded-For only when sent by a trusted proxy;
+	// otherwise any caller could pick the IP its login failures count against
+	if err := r.SetTrustedProxies(app.config.trustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
+
 	// Apply middlewares
 	r.Use(middleware.RequestID()) // assign unique id to each request
 	r.Use(middleware.RealIP())    // get real client ip
@@ -24,6 +34,9 @@ func (app *application) mount() http.Handler {
 	r.Use(middleware.CORS())      // handle CORS
 	r.Use(middleware.Recoverer()) // recover from panics
 
+	// Only trust callers forwarded by the BFF with a valid signature
+	r.Use(middleware.IdentityContext(app.config.identity))
+
 	r.Use(middleware.Timeout(60 * time.Second)) // set timeout for requests
 
 	// Health check endpoint
@@ -37,10 +50,7 @@ func (app *application) mount() http.Handler {
 	// Database health check
 	r.GET("/health/db", func(c *gin.Context) {
 		if err := app.config.db.pool.Ping(c.Request.Context()); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "unhealthy",
-				"error":  "database connection failed",
-			})
+			problem.Abort(c, http.StatusServiceUnavailable, problem.TypeDefault, "database connection failed")
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{
@@ -51,8 +61,17 @@ func (app *application) mount() http.Handler {
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
+		// Roles come first: their service authorizes the other domains
+		rolesService := roles.NewService(roles.NewPostgresRepository(app.config.db.pool))
+		roles.RegisterRoutes(v1, rolesService)
+
+		// Replay stored responses for retried POSTs carrying an Idempotency-Key.
+		// Only mounted where the stored response holds no credentials or tokens.
+		idempotent := idempotency.Middleware(idempotency.NewPostgresRepository(app.config.db.pool), app.config.idempotency)
+
 		// Register domain routes with database connection
-		users.RegisterRoutes(v1, app.config.db.pool)
+		users.RegisterRoutes(v1, app.config.db.pool, app.config.users, rolesService, idempotent)
+		audit.RegisterRoutes(v1, app.config.db.pool, rolesService)
 		// Future domains can be registered here:
 		// posts.RegisterRoutes(v1, app.config.db.pool)
 		// products.RegisterRoutes(v1, app.config.db.pool)
@@ -80,8 +99,12 @@ type application struct {
 }
 
 type config struct {
-	addr string   // server port
-	db   dbConfig // database configuration
+	addr           string                    // server port
+	trustedProxies []string                  // proxies allowed to set X-Forwarded-For
+	db             dbConfig                  // database configuration
+	users          users.Config              // users domain settings
+	identity       middleware.IdentityConfig // trust settings for BFF-forwarded callers
+	idempotency    idempotency.Config        // retention of Idempotency-Key responses
 }
 
 type dbConfig struct {
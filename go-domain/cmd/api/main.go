@@ -1,10 +1,19 @@
 package main
 
 import (
+	"encoding/base64"
 	"log/slog"
+	"math"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/database"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/idempotency"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/users"
 	"github.com/joho/godotenv"
 )
 
@@ -59,13 +68,67 @@ func main() {
 	}
 	defer database.Close(db)
 
+	// Users domain settings
+	usersCfg := users.DefaultConfig()
+	usersCfg.Argon2.Memory = uint32(mustGetEnvIntInRange("ARGON2_MEMORY_KIB", int64(usersCfg.Argon2.Memory), 1, math.MaxUint32))
+	usersCfg.Argon2.Iterations = uint32(mustGetEnvIntInRange("ARGON2_ITERATIONS", int64(usersCfg.Argon2.Iterations), 1, math.MaxUint32))
+	usersCfg.Argon2.Parallelism = uint8(mustGetEnvIntInRange("ARGON2_PARALLELISM", int64(usersCfg.Argon2.Parallelism), 1, math.MaxUint8))
+	if err := usersCfg.Argon2.Validate(); err != nil {
+		slog.Error("Invalid password hashing settings", "error", err)
+		os.Exit(1)
+	}
+	usersCfg.PasswordPolicy.MinLength = getEnvInt("PASSWORD_MIN_LENGTH", usersCfg.PasswordPolicy.MinLength)
+	usersCfg.PasswordPolicy.RequireUpper = getEnvBool("PASSWORD_REQUIRE_UPPER", usersCfg.PasswordPolicy.RequireUpper)
+	usersCfg.PasswordPolicy.RequireLower = getEnvBool("PASSWORD_REQUIRE_LOWER", usersCfg.PasswordPolicy.RequireLower)
+	usersCfg.PasswordPolicy.RequireDigit = getEnvBool("PASSWORD_REQUIRE_DIGIT", usersCfg.PasswordPolicy.RequireDigit)
+	usersCfg.PasswordPolicy.RequireSymbol = getEnvBool("PASSWORD_REQUIRE_SYMBOL", usersCfg.PasswordPolicy.RequireSymbol)
+	usersCfg.PasswordPolicy.BreachedPasswordsDir = os.Getenv("BREACHED_PASSWORDS_DIR")
+	usersCfg.Lockout.UserThreshold = getEnvInt("LOGIN_LOCKOUT_USER_THRESHOLD", usersCfg.Lockout.UserThreshold)
+	usersCfg.Lockout.IPThreshold = getEnvInt("LOGIN_LOCKOUT_IP_THRESHOLD", usersCfg.Lockout.IPThreshold)
+	usersCfg.Lockout.Window = getEnvDuration("LOGIN_LOCKOUT_WINDOW", usersCfg.Lockout.Window)
+	usersCfg.Lockout.BaseDuration = getEnvDuration("LOGIN_LOCKOUT_BASE_DURATION", usersCfg.Lockout.BaseDuration)
+	usersCfg.Lockout.MaxDuration = getEnvDuration("LOGIN_LOCKOUT_MAX_DURATION", usersCfg.Lockout.MaxDuration)
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		usersCfg.TOTP.Issuer = issuer
+	}
+	if key := os.Getenv("TOTP_ENCRYPTION_KEY"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != 32 {
+			slog.Error("TOTP_ENCRYPTION_KEY must be 32 bytes, base64 encoded")
+			os.Exit(1)
+		}
+		usersCfg.TOTP.EncryptionKey = decoded
+	} else {
+		slog.Warn("TOTP_ENCRYPTION_KEY not set, multi-factor enrollment is disabled")
+	}
+
+	// Identity forwarded by the BFF is only trusted with a valid signature
+	identityCfg := middleware.DefaultIdentityConfig()
+	identityCfg.MaxSkew = getEnvDuration("IDENTITY_MAX_SKEW", identityCfg.MaxSkew)
+	if secret := os.Getenv("IDENTITY_SHARED_SECRET"); secret != "" {
+		identityCfg.Secret = []byte(secret)
+	} else {
+		slog.Warn("IDENTITY_SHARED_SECRET not set, all forwarded identities will be rejected")
+	}
+
+	// Responses to POSTs with an Idempotency-Key are replayed until the TTL passes
+	idempotencyCfg := idempotency.DefaultConfig()
+	idempotencyCfg.TTL = getEnvDuration("IDEMPOTENCY_KEY_TTL", idempotencyCfg.TTL)
+
+	// X-Forwarded-For is only honored from these proxies, e.g. the BFF
+	trustedProxies := mustGetEnvProxies("TRUSTED_PROXIES")
+
 	// Create application configuration
 	cfg := config{
-		addr: addr,
+		addr:           addr,
+		trustedProxies: trustedProxies,
 		db: dbConfig{
 			dsn:  dsn,
 			pool: db,
 		},
+		users:       usersCfg,
+		identity:    identityCfg,
+		idempotency: idempotencyCfg,
 	}
 
 	// Create and run application
@@ -87,3 +150,80 @@ func maskDSN(dsn string) string {
 	}
 	return dsn
 }
+
+// getEnvInt reads a positive integer from the environment, falling back on missing or invalid values
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		slog.Warn("Ignoring invalid environment variable", "key", key, "value", value)
+		return fallback
+	}
+	return n
+}
+
+// mustGetEnvIntInRange reads an integer within [low, high] from the environment.
+// Settings that would be truncated or break at runtime exit instead of falling back.
+func mustGetEnvIntInRange(key string, fallback, low, high int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < low || n > high {
+		slog.Error("Invalid environment variable", "key", key, "value", value, "min", low, "max", high)
+		os.Exit(1)
+	}
+	return n
+}
+
+// mustGetEnvProxies reads a comma separated list of proxy IPs or CIDRs from
+// the environment. Missing means no proxy is trusted; invalid entries exit.
+func mustGetEnvProxies(key string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv(key), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				slog.Error("Invalid environment variable", "key", key, "value", proxy)
+				os.Exit(1)
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// getEnvBool reads a boolean from the environment, falling back on missing or invalid values
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid environment variable", "key", key, "value", value)
+		return fallback
+	}
+	return b
+}
+
+// getEnvDuration reads a positive duration such as "15m" from the environment, falling back on missing or invalid values
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		slog.Warn("Ignoring invalid environment variable", "key", key, "value", value)
+		return fallback
+	}
+	return d
+}
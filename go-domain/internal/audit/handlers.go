@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// ListEvents handles GET /audit?entity=user&id=...&actor_id=...&action=...&since=...&until=...
+func (h *handler) ListEvents(c *gin.Context) {
+	// Parse pagination parameters
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	limit = pageLimit(limit)
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	filter, fieldErrors := parseFilter(c)
+	if len(fieldErrors) > 0 {
+		problem.Validation(c, "Invalid audit filter", fieldErrors)
+		return
+	}
+
+	events, err := h.service.ListEvents(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		slog.Error("Failed to list audit events", "error", err)
+		problem.Internal(c, "Failed to fetch audit events")
+		return
+	}
+
+	total, err := h.service.CountEvents(c.Request.Context(), filter)
+	if err != nil {
+		slog.Error("Failed to count audit events", "error", err)
+		// Continue even if count fails
+		total = 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"pagination": gin.H{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// pageLimit clamps a requested page size to (0, MaxPageSize], using the
+// default for missing or invalid sizes
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+// parseFilter reads the audit filter from the query string, collecting
+// an error for every invalid parameter
+func parseFilter(c *gin.Context) (ListFilter, []problem.FieldError) {
+	filter := ListFilter{
+		EntityType: c.Query("entity"),
+		Action:     c.Query("action"),
+	}
+	var fieldErrors []problem.FieldError
+
+	parseUUID := func(param string) *uuid.UUID {
+		raw := c.Query(param)
+		if raw == "" {
+			return nil
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: param, Rule: "uuid", Message: "must be a valid UUID"})
+			return nil
+		}
+		return &id
+	}
+	parseTime := func(param string) *time.Time {
+		raw := c.Query(param)
+		if raw == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: param, Rule: "datetime", Message: "must be an RFC 3339 timestamp"})
+			return nil
+		}
+		return &t
+	}
+
+	filter.EntityID = parseUUID("id")
+	filter.ActorID = parseUUID("actor_id")
+	filter.Since = parseTime("since")
+	filter.Until = parseTime("until")
+
+	if filter.EntityID != nil && filter.EntityType == "" {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "entity", Rule: "required_with", Message: "is required when filtering by id"})
+	}
+
+	return filter, fieldErrors
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types recorded in the audit log
+const (
+	EntityUser = "user"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate         = "create"
+	ActionUpdate         = "update"
+	ActionDelete         = "delete"
+	ActionStatusChange   = "status_change"
+	ActionPasswordChange = "password_change"
+	ActionEmailVerified  = "email_verified"
+)
+
+// Page sizes for audit event listings
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// redacted replaces the values of sensitive fields in a diff
+const redacted = "[REDACTED]"
+
+// Change is the before and after value of one field. Before is nil for
+// created entities.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Entry is a change about to be recorded. Actor, request ID and client IP
+// are taken from the request context when it is written.
+type Entry struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Action     string
+	Changes    map[string]Change
+}
+
+// Event is a recorded audit log entry
+type Event struct {
+	ID          int64             `json:"id"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+	EntityType  string            `json:"entity_type"`
+	EntityID    uuid.UUID         `json:"entity_id"`
+	Action      string            `json:"action"`
+	ActorID     *uuid.UUID        `json:"actor_id,omitempty"` // nil for anonymous callers, e.g. sign up
+	ActorTenant *string           `json:"actor_tenant,omitempty"`
+	RequestID   *string           `json:"request_id,omitempty"`
+	ClientIP    *string           `json:"client_ip,omitempty"`
+	Changes     map[string]Change `json:"changes"`
+}
+
+// ListFilter narrows the events returned by List and Count
+type ListFilter struct {
+	EntityType string     // empty matches every entity type
+	EntityID   *uuid.UUID // nil matches every entity
+	ActorID    *uuid.UUID // nil matches every actor
+	Action     string     // empty matches every action
+	Since      *time.Time // inclusive
+	Until      *time.Time // exclusive
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// filterClause matches ListFilter; arguments come from filterArgs
+const filterClause = `
+	WHERE ($1 = '' OR entity_type = $1)
+	  AND ($2::uuid IS NULL OR entity_id = $2)
+	  AND ($3::uuid IS NULL OR actor_id = $3)
+	  AND ($4 = '' OR action = $4)
+	  AND ($5::timestamptz IS NULL OR occurred_at >= $5)
+	  AND ($6::timestamptz IS NULL OR occurred_at < $6)
+`
+
+// postgresRepository implements the Repository interface using PostgreSQL
+type postgresRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+// List retrieves events matching the filter, newest first
+func (r *postgresRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Event, error) {
+	// Set default limit if not provided
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, occurred_at, entity_type, entity_id, action, actor_id,
+		       actor_tenant, request_id, client_ip, changes
+		FROM audit_events
+	` + filterClause + `
+		ORDER BY id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	rows, err := r.db.Query(ctx, query, append(filterArgs(filter), limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*Event{}
+	for rows.Next() {
+		event := &Event{}
+		err := rows.Scan(
+			&event.ID,
+			&event.OccurredAt,
+			&event.EntityType,
+			&event.EntityID,
+			&event.Action,
+			&event.ActorID,
+			&event.ActorTenant,
+			&event.RequestID,
+			&event.ClientIP,
+			&event.Changes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// Count returns the number of events matching the filter
+func (r *postgresRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	query := `SELECT COUNT(*) FROM audit_events` + filterClause
+
+	var count int64
+	if err := r.db.QueryRow(ctx, query, filterArgs(filter)...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	return count, nil
+}
+
+// filterArgs returns the arguments for filterClause
+func filterArgs(filter ListFilter) []any {
+	return []any{
+		filter.EntityType,
+		filter.EntityID,
+		filter.ActorID,
+		filter.Action,
+		filter.Since,
+		filter.Until,
+	}
+}
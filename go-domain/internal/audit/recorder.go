@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/jackc/pgx/v5"
+)
+
+// Diff compares two field snapshots and returns the fields that changed.
+// Either snapshot may be nil (create or hard delete). Fields listed in
+// sensitive are still reported when they change, but with redacted values.
+func Diff(before, after map[string]any, sensitive ...string) map[string]Change {
+	changes := map[string]Change{}
+
+	record := func(field string) {
+		if _, done := changes[field]; done {
+			return
+		}
+		// Missing fields read as nil, so nil to nil is not a change
+		old, cur := before[field], after[field]
+		if reflect.DeepEqual(old, cur) {
+			return
+		}
+		if slices.Contains(sensitive, field) {
+			old, cur = redactValue(old), redactValue(cur)
+		}
+		changes[field] = Change{Before: old, After: cur}
+	}
+
+	for field := range before {
+		record(field)
+	}
+	for field := range after {
+		record(field)
+	}
+
+	return changes
+}
+
+// redactValue hides a sensitive value but keeps nil visible, so the diff
+// still shows whether a value was set or cleared
+func redactValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redacted
+}
+
+// Record writes entry inside tx, the transaction making the change, so the
+// change and its audit event commit or roll back together. The actor comes
+// from middleware.IdentityContext and request metadata from RequestID/RealIP.
+func Record(ctx context.Context, tx pgx.Tx, entry Entry) error {
+	event := Event{
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		Changes:    entry.Changes,
+	}
+	if event.Changes == nil {
+		event.Changes = map[string]Change{}
+	}
+	if caller, ok := middleware.IdentityFrom(ctx); ok {
+		event.ActorID = &caller.UserID
+		if caller.TenantID != "" {
+			event.ActorTenant = &caller.TenantID
+		}
+	}
+	if requestID := middleware.RequestIDFrom(ctx); requestID != "" {
+		event.RequestID = &requestID
+	}
+	if clientIP := middleware.ClientIPFrom(ctx); clientIP != "" {
+		event.ClientIP = &clientIP
+	}
+
+	query := `
+		INSERT INTO audit_events
+			(entity_type, entity_id, action, actor_id, actor_tenant, request_id, client_ip, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		event.EntityType,
+		event.EntityID,
+		event.Action,
+		event.ActorID,
+		event.ActorTenant,
+		event.RequestID,
+		event.ClientIP,
+		event.Changes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{"username": "alice", "password_hash": "$argon2id$old", "last_name": nil}
+
+	tests := []struct {
+		name   string
+		before map[string]any
+		after  map[string]any
+		want   map[string]Change
+	}{
+		{name: "no change", before: before, after: before, want: map[string]Change{}},
+		{
+			name:   "plain field",
+			before: before,
+			after:  map[string]any{"username": "alicia", "password_hash": "$argon2id$old", "last_name": nil},
+			want:   map[string]Change{"username": {Before: "alice", After: "alicia"}},
+		},
+		{
+			name:   "sensitive field is redacted",
+			before: before,
+			after:  map[string]any{"username": "alice", "password_hash": "$argon2id$new", "last_name": nil},
+			want:   map[string]Change{"password_hash": {Before: redacted, After: redacted}},
+		},
+		{
+			name:   "sensitive field set on create",
+			before: nil,
+			after:  map[string]any{"username": "alice", "password_hash": "$argon2id$new"},
+			want: map[string]Change{
+				"username":      {Before: nil, After: "alice"},
+				"password_hash": {Before: nil, After: redacted},
+			},
+		},
+		{
+			name:   "cleared field",
+			before: map[string]any{"last_name": "Liddell"},
+			after:  map[string]any{"last_name": nil},
+			want:   map[string]Change{"last_name": {Before: "Liddell", After: nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after, "password_hash")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package audit
+
+import "context"
+
+// Repository defines the interface for reading the audit log.
+// Events are written with Record inside the caller's transaction.
+type Repository interface {
+	// List retrieves events matching the filter, newest first
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]*Event, error)
+
+	// Count returns the number of events matching the filter
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+}
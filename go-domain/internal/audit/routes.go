@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RegisterRoutes registers the audit log routes. Reading requires audit:read.
+func RegisterRoutes(router *gin.RouterGroup, db *pgxpool.Pool, authz roles.Authorizer) {
+	// Create repository with database connection
+	repo := NewPostgresRepository(db)
+
+	// Create service with repository
+	service := NewService(repo)
+
+	// Create handler with service
+	handler := NewHandler(service)
+
+	canRead := roles.RequirePermission(authz, roles.PermAuditRead)
+
+	// Register routes
+	router.GET("/audit", canRead, handler.ListEvents) // GET /api/v1/audit
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+)
+
+type Service interface {
+	ListEvents(ctx context.Context, filter ListFilter, limit, offset int) ([]*Event, error)
+	CountEvents(ctx context.Context, filter ListFilter) (int64, error)
+}
+
+type svc struct {
+	repo Repository
+}
+
+// NewService creates a new audit service
+func NewService(repo Repository) Service {
+	return &svc{
+		repo: repo,
+	}
+}
+
+// ListEvents retrieves audit events matching the filter, newest first
+func (s *svc) ListEvents(ctx context.Context, filter ListFilter, limit, offset int) ([]*Event, error) {
+	events, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return events, nil
+}
+
+// CountEvents returns the number of audit events matching the filter
+func (s *svc) CountEvents(ctx context.Context, filter ListFilter) (int64, error) {
+	count, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	return count, nil
+}
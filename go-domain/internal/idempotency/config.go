@@ -0,0 +1,20 @@
+package idempotency
+
+import "time"
+
+// Config holds the idempotency key settings
+type Config struct {
+	// TTL is how long a key is remembered. Retries after that run again.
+	TTL time.Duration
+
+	// PurgeInterval is the minimum time between deletions of expired keys
+	PurgeInterval time.Duration
+}
+
+// DefaultConfig returns idempotency settings with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		TTL:           24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+}
@@ -0,0 +1,198 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency headers
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed" // "true" on stored responses
+)
+
+// maxKeyLength matches the idempotency_keys.key column
+const maxKeyLength = 255
+
+// storeTimeout bounds the writes made after the handler ran. They use a
+// context detached from the request, which may already be cancelled when the
+// client gave up waiting (the case retries exist for).
+const storeTimeout = 5 * time.Second
+
+// Middleware makes POST requests carrying an Idempotency-Key safe to retry.
+//
+// The first request with a key runs normally and its response is stored.
+// Retries with the same key and the same method, path and body get the
+// stored response back with Idempotent-Replayed: true. Reusing a key for a
+// different request is rejected with 422, and a retry that arrives while the
+// first request is still running gets 409. Keys are scoped to the calling
+// user and forgotten after cfg.TTL.
+//
+// 5xx responses are not stored, so a retry after a server error runs again.
+// Responses are stored as sent, so mount it only on routes whose responses
+// hold no secrets and whose replay skips no check (not logins, MFA or tokens).
+func Middleware(repo Repository, cfg Config) gin.HandlerFunc {
+	purge := &purger{repo: repo, interval: cfg.PurgeInterval}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			problem.BadRequest(c, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		// Read the body for the fingerprint and put it back for the handler
+		body, err := c.GetRawData()
+		if err != nil {
+			problem.BadRequest(c, "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		caller := ""
+		if id, ok := middleware.IdentityFrom(c.Request.Context()); ok {
+			caller = id.UserID.String()
+		}
+		sum := fingerprint(c.Request, body)
+
+		record, claimed, err := repo.Claim(c.Request.Context(), caller, key, sum, cfg.TTL)
+		if err != nil {
+			slog.Error("Failed to claim idempotency key", "error", err)
+			problem.Internal(c, "Failed to check idempotency key")
+			return
+		}
+		if !claimed {
+			replay(c, record, sum)
+			return
+		}
+
+		// The key must end up completed or released, even if the handler
+		// panics, or retries would get 409 until it expires
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			ctx, cancel := detached(c.Request.Context())
+			defer cancel()
+			if err := repo.Release(ctx, caller, key); err != nil {
+				slog.Error("Failed to release idempotency key", "error", err)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		ctx, cancel := detached(c.Request.Context())
+		defer cancel()
+		contentType := recorder.Header().Get("Content-Type")
+		if err := repo.Complete(ctx, caller, key, recorder.Status(), contentType, recorder.body.Bytes()); err != nil {
+			slog.Error("Failed to store idempotent response", "error", err)
+			return
+		}
+		completed = true
+
+		purge.maybe(context.WithoutCancel(c.Request.Context()))
+	}
+}
+
+// replay answers a request whose key is already claimed
+func replay(c *gin.Context, record *Record, sum []byte) {
+	switch {
+	case !bytes.Equal(record.Fingerprint, sum):
+		problem.Validation(c, "Idempotency-Key was already used for a different request", []problem.FieldError{{
+			Field:   HeaderKey,
+			Rule:    "same_request",
+			Message: "must not be reused with a different request",
+		}})
+	case record.InFlight():
+		c.Header("Retry-After", "1")
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "A request with this Idempotency-Key is still in progress")
+	default:
+		c.Header(HeaderReplayed, "true")
+		c.Data(*record.StatusCode, record.ContentType, record.Body)
+		c.Abort()
+	}
+}
+
+// fingerprint identifies a request by method, path with query and body
+func fingerprint(r *http.Request, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(r.Method + "\n" + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// detached returns a context for storage writes that outlives the request
+func detached(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+}
+
+// bodyRecorder keeps a copy of the response body while writing it through
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// purger deletes expired keys in the background, at most once per interval
+type purger struct {
+	repo     Repository
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// maybe starts a purge if the last one is at least interval ago
+func (p *purger) maybe(ctx context.Context) {
+	p.mu.Lock()
+	if time.Since(p.last) < p.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.last = time.Now()
+	p.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+		defer cancel()
+
+		deleted, err := p.repo.DeleteExpired(ctx)
+		if err != nil {
+			slog.Error("Failed to purge idempotency keys", "error", err)
+			return
+		}
+		if deleted > 0 {
+			slog.Info("Purged expired idempotency keys", "count", deleted)
+		}
+	}()
+}
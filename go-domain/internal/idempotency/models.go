@@ -0,0 +1,20 @@
+package idempotency
+
+import "time"
+
+// Record is the stored outcome of a request made with an Idempotency-Key
+type Record struct {
+	Caller      string // user id of the caller, empty for anonymous requests
+	Key         string
+	Fingerprint []byte // SHA-256 of method, path and body
+	StatusCode  *int   // nil while the first request is still in flight
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// InFlight reports whether the request that claimed the key has not finished
+func (r *Record) InFlight() bool {
+	return r.StatusCode == nil
+}
@@ -0,0 +1,114 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresRepository implements the Repository interface using PostgreSQL
+type postgresRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+// Claim inserts an in-flight record for key, taking over an expired one.
+// Concurrent claims of the same key are serialized by the primary key, so
+// exactly one of them wins.
+func (r *postgresRepository) Claim(ctx context.Context, caller, key string, fingerprint []byte, ttl time.Duration) (*Record, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (caller, key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		ON CONFLICT (caller, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status_code = NULL,
+		    content_type = NULL,
+		    response_body = NULL,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+	`
+
+	result, err := r.db.Exec(ctx, query, caller, key, fingerprint, ttl.Seconds())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if result.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	// The key is live; return what is stored for it
+	query = `
+		SELECT caller, key, fingerprint, status_code, COALESCE(content_type, ''),
+		       response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE caller = $1 AND key = $2
+	`
+
+	record := &Record{}
+	err = r.db.QueryRow(ctx, query, caller, key).Scan(
+		&record.Caller,
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released between the two statements; the caller may retry
+			return nil, false, fmt.Errorf("idempotency key released concurrently: %w", err)
+		}
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return record, false, nil
+}
+
+// Complete stores the response of a claimed request
+func (r *postgresRepository) Complete(ctx context.Context, caller, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5
+		WHERE caller = $1 AND key = $2 AND status_code IS NULL
+	`
+
+	if _, err := r.db.Exec(ctx, query, caller, key, statusCode, contentType, body); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release deletes an in-flight record
+func (r *postgresRepository) Release(ctx context.Context, caller, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE caller = $1 AND key = $2 AND status_code IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, caller, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes expired keys
+func (r *postgresRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for idempotency key storage
+type Repository interface {
+	// Claim reserves key for a new request. It returns (nil, true) when the key
+	// was unused or expired, and the existing record with false otherwise.
+	Claim(ctx context.Context, caller, key string, fingerprint []byte, ttl time.Duration) (*Record, bool, error)
+
+	// Complete stores the response of a claimed request
+	Complete(ctx context.Context, caller, key string, statusCode int, contentType string, body []byte) error
+
+	// Release forgets a claimed key whose request did not complete, so it can be retried
+	Release(ctx context.Context, caller, key string) error
+
+	// DeleteExpired deletes expired keys and returns how many were removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Identity headers set by the BFF for authenticated callers
+const (
+	HeaderIdentityUserID    = "X-Identity-User-ID"
+	HeaderIdentityRoles     = "X-Identity-Roles" // comma separated
+	HeaderIdentityTenant    = "X-Identity-Tenant"
+	HeaderIdentityTimestamp = "X-Identity-Timestamp" // unix seconds
+	HeaderIdentitySignature = "X-Identity-Signature" // hex HMAC-SHA256
+)
+
+// identitySignatureVersion prefixes the signed payload so the format can change
+const identitySignatureVersion = "v1"
+
+// Identity is the caller as authenticated by the BFF
+type Identity struct {
+	UserID   uuid.UUID
+	Roles    []string
+	TenantID string // empty when the caller has no tenant
+}
+
+// IdentityConfig configures verification of forwarded identities
+type IdentityConfig struct {
+	// Secret is shared with the BFF. Without it no identity is trusted.
+	Secret []byte
+
+	// MaxSkew bounds how old (or how far in the future) a signature may be.
+	// It is also the window in which a captured header set can be replayed.
+	MaxSkew time.Duration
+}
+
+// DefaultIdentityConfig returns identity settings with sensible defaults
+func DefaultIdentityConfig() IdentityConfig {
+	return IdentityConfig{
+		MaxSkew: 5 * time.Minute,
+	}
+}
+
+// identityKey is the request context key for the caller's Identity
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom returns the verified caller, if the request had one
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// IdentityContext verifies identity headers forwarded by the BFF and stores
+// the caller in the request context (see IdentityFrom).
+//
+// The BFF signs "v1\n<timestamp>\n<user id>\n<roles>\n<tenant>" with
+// HMAC-SHA256 using the shared secret. Requests without identity headers
+// continue anonymously; requests with a missing, invalid or stale signature
+// are rejected with 401 so a direct caller cannot claim to be someone else.
+func IdentityContext(cfg IdentityConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(HeaderIdentityUserID)
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		id, ok := verifyIdentity(cfg, c.Request.Header, time.Now())
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, problem.TypeUnauthorized, "Caller identity could not be verified")
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithIdentity(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// verifyIdentity checks the signature and timestamp and parses the identity
+func verifyIdentity(cfg IdentityConfig, header http.Header, now time.Time) (Identity, bool) {
+	if len(cfg.Secret) == 0 {
+		return Identity{}, false
+	}
+
+	userID := header.Get(HeaderIdentityUserID)
+	roles := header.Get(HeaderIdentityRoles)
+	tenant := header.Get(HeaderIdentityTenant)
+	timestamp := header.Get(HeaderIdentityTimestamp)
+
+	signature, err := hex.DecodeString(header.Get(HeaderIdentitySignature))
+	if err != nil || len(signature) == 0 {
+		return Identity{}, false
+	}
+	if !hmac.Equal(signature, SignIdentity(cfg.Secret, timestamp, userID, roles, tenant)) {
+		return Identity{}, false
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Identity{}, false
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > cfg.MaxSkew || skew < -cfg.MaxSkew {
+		return Identity{}, false
+	}
+
+	parsed, err := uuid.Parse(userID)
+	if err != nil {
+		return Identity{}, false
+	}
+
+	id := Identity{UserID: parsed, TenantID: tenant}
+	for _, role := range strings.Split(roles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			id.Roles = append(id.Roles, role)
+		}
+	}
+
+	return id, true
+}
+
+// SignIdentity computes the signature the BFF sends in X-Identity-Signature
+func SignIdentity(secret []byte, timestamp, userID, roles, tenant string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{identitySignatureVersion, timestamp, userID, roles, tenant}, "\n")))
+	return mac.Sum(nil)
+}
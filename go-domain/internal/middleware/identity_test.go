@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifyIdentity(t *testing.T) {
+	secret := []byte("shared-secret")
+	userID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	now := time.Unix(1700000000, 0)
+	cfg := IdentityConfig{Secret: secret, MaxSkew: 5 * time.Minute}
+
+	// signed builds headers the way the BFF does, at time at
+	signed := func(at time.Time, roles string) http.Header {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		header := http.Header{}
+		header.Set(HeaderIdentityUserID, userID)
+		header.Set(HeaderIdentityRoles, roles)
+		header.Set(HeaderIdentityTenant, "acme")
+		header.Set(HeaderIdentityTimestamp, timestamp)
+		header.Set(HeaderIdentitySignature, hex.EncodeToString(SignIdentity(secret, timestamp, userID, roles, "acme")))
+		return header
+	}
+
+	tests := []struct {
+		name   string
+		cfg    IdentityConfig
+		header http.Header
+		want   bool
+	}{
+		{
+			name: "signature from bff-node/src/identity.js",
+			cfg:  cfg,
+			header: func() http.Header {
+				header := http.Header{}
+				header.Set(HeaderIdentityUserID, userID)
+				header.Set(HeaderIdentityRoles, "admin,support")
+				header.Set(HeaderIdentityTenant, "acme")
+				header.Set(HeaderIdentityTimestamp, "1700000000")
+				header.Set(HeaderIdentitySignature, "6593ab6a4329db1783f93e3144077805bcd4b6a93a7647c77bb61b4114f49dca")
+				return header
+			}(),
+			want: true,
+		},
+		{name: "signed now", cfg: cfg, header: signed(now, "admin,support"), want: true},
+		{name: "at the edge of the skew", cfg: cfg, header: signed(now.Add(-5*time.Minute), "admin,support"), want: true},
+		{name: "too old", cfg: cfg, header: signed(now.Add(-5*time.Minute-time.Second), "admin,support"), want: false},
+		{name: "too far in the future", cfg: cfg, header: signed(now.Add(5*time.Minute+time.Second), "admin,support"), want: false},
+		{
+			name: "tampered roles",
+			cfg:  cfg,
+			header: func() http.Header {
+				header := signed(now, "support")
+				header.Set(HeaderIdentityRoles, "support,admin")
+				return header
+			}(),
+			want: false,
+		},
+		{
+			name: "tampered timestamp",
+			cfg:  cfg,
+			header: func() http.Header {
+				header := signed(now.Add(-time.Hour), "support")
+				header.Set(HeaderIdentityTimestamp, strconv.FormatInt(now.Unix(), 10))
+				return header
+			}(),
+			want: false,
+		},
+		{name: "missing secret", cfg: IdentityConfig{MaxSkew: cfg.MaxSkew}, header: signed(now, "admin"), want: false},
+		{name: "wrong secret", cfg: IdentityConfig{Secret: []byte("other"), MaxSkew: cfg.MaxSkew}, header: signed(now, "admin"), want: false},
+		{
+			name: "missing signature",
+			cfg:  cfg,
+			header: func() http.Header {
+				header := signed(now, "admin")
+				header.Del(HeaderIdentitySignature)
+				return header
+			}(),
+			want: false,
+		},
+		{
+			name: "malformed signature",
+			cfg:  cfg,
+			header: func() http.Header {
+				header := signed(now, "admin")
+				header.Set(HeaderIdentitySignature, "not-hex")
+				return header
+			}(),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := verifyIdentity(tt.cfg, tt.header, now)
+			if ok != tt.want {
+				t.Fatalf("verifyIdentity() ok = %v, want %v", ok, tt.want)
+			}
+			if !ok {
+				return
+			}
+			want := Identity{UserID: uuid.MustParse(userID), Roles: []string{"admin", "support"}, TenantID: "acme"}
+			if id.UserID != want.UserID || !slices.Equal(id.Roles, want.Roles) || id.TenantID != want.TenantID {
+				t.Errorf("verifyIdentity() = %+v, want %+v", id, want)
+			}
+		})
+	}
+}
+
+func TestSignIdentity(t *testing.T) {
+	tests := []struct {
+		name                           string
+		timestamp, user, roles, tenant string
+		want                           string
+	}{
+		{
+			name:      "matches bff-node/src/identity.js",
+			timestamp: "1700000000",
+			user:      "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			roles:     "admin,support",
+			tenant:    "acme",
+			want:      "6593ab6a4329db1783f93e3144077805bcd4b6a93a7647c77bb61b4114f49dca",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hex.EncodeToString(SignIdentity([]byte("shared-secret"), tt.timestamp, tt.user, tt.roles, tt.tenant))
+			if got != tt.want {
+				t.Errorf("SignIdentity() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
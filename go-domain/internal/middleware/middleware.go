@@ -5,10 +5,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// Request context keys, so services can read request metadata without gin
+type (
+	requestIDKey struct{}
+	clientIPKey  struct{}
+)
+
 // RequestID middleware adds a unique request ID to each request
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -17,6 +24,7 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 		c.Set("requestID", requestID)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, requestID))
 		c.Header("X-Request-ID", requestID)
 		c.Next()
 	}
@@ -27,10 +35,23 @@ func RealIP() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := c.ClientIP()
 		c.Set("clientIP", clientIP)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), clientIPKey{}, clientIP))
 		c.Next()
 	}
 }
 
+// RequestIDFrom returns the request ID set by RequestID, or ""
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ClientIPFrom returns the client IP set by RealIP, or ""
+func ClientIPFrom(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
 // Logger middleware logs request details
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -66,7 +87,8 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, If-None-Match, Idempotency-Key")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After, Idempotent-Replayed")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -83,9 +105,7 @@ func Recoverer() gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				fmt.Printf("[PANIC] %v\n", err)
-				c.AbortWithStatusJSON(500, gin.H{
-					"error": "Internal Server Error",
-				})
+				problem.Internal(c, "Internal Server Error")
 			}
 		}()
 		c.Next()
@@ -0,0 +1,177 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentType is the media type for RFC 7807 problem details
+const ContentType = "application/problem+json"
+
+// requestIDKey is the gin context key set by middleware.RequestID
+const requestIDKey = "requestID"
+
+// Problem types shared across domains
+const (
+	TypeDefault            = "about:blank"
+	TypeValidation         = "/problems/validation-error"
+	TypeUnauthorized       = "/problems/unauthorized"
+	TypeForbidden          = "/problems/forbidden"
+	TypeNotFound           = "/problems/not-found"
+	TypeConflict           = "/problems/conflict"
+	TypePreconditionFailed = "/problems/precondition-failed"
+	TypeTooManyRequests    = "/problems/too-many-requests"
+	TypeInternal           = "/problems/internal-error"
+)
+
+// Problem is an RFC 7807 problem details response body
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a single invalid field in a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// New creates a problem with the standard title for the status code
+func New(status int, problemType, detail string) *Problem {
+	if problemType == "" {
+		problemType = TypeDefault
+	}
+	return &Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Write aborts the request and renders the problem as application/problem+json.
+// Instance and RequestID are filled from the current request when empty.
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = c.GetString(requestIDKey)
+	}
+	// gin only sets Content-Type when it is not already present
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Abort writes a problem built from status, type and detail
+func Abort(c *gin.Context, status int, problemType, detail string) {
+	Write(c, New(status, problemType, detail))
+}
+
+// BadRequest writes a 400 problem
+func BadRequest(c *gin.Context, detail string) {
+	Abort(c, http.StatusBadRequest, TypeDefault, detail)
+}
+
+// Internal writes a 500 problem
+func Internal(c *gin.Context, detail string) {
+	Abort(c, http.StatusInternalServerError, TypeInternal, detail)
+}
+
+// Validation writes a 422 problem listing the invalid fields
+func Validation(c *gin.Context, detail string, fields []FieldError) {
+	p := New(http.StatusUnprocessableEntity, TypeValidation, detail)
+	p.Errors = fields
+	Write(c, p)
+}
+
+// BindError writes the problem for a failed ShouldBind* call.
+// Validator failures become 422 with per-field errors; anything else
+// (malformed JSON, wrong types) is a 400.
+func BindError(c *gin.Context, err error) {
+	if fields := FieldErrors(err); len(fields) > 0 {
+		Validation(c, "Request body failed validation", fields)
+		return
+	}
+	BadRequest(c, "Invalid request body")
+}
+
+// FieldErrors extracts per-field errors from gin's validator.
+// It returns nil when err is not a validation error.
+func FieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return fields
+}
+
+// JSONFieldName names struct fields by their json tag so field errors
+// match the request body. Register it with validator.RegisterTagNameFunc.
+func JSONFieldName(f reflect.StructField) string {
+	name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// MessageFunc builds the message for a failed validation rule
+type MessageFunc func(fe validator.FieldError) string
+
+// messages holds messages for custom rules, keyed by validator tag
+var messages = map[string]MessageFunc{}
+
+// RegisterMessage sets the message used for a custom validation rule.
+// It is meant to be called during startup alongside RegisterValidation.
+func RegisterMessage(tag string, fn MessageFunc) {
+	messages[tag] = fn
+}
+
+// message returns a human readable message for a validator failure
+func message(fe validator.FieldError) string {
+	if fn, ok := messages[fe.Tag()]; ok {
+		return fn(fe)
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters", fe.Param())
+	case "numeric":
+		return "must contain only digits"
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
@@ -0,0 +1,22 @@
+package roles
+
+import "errors"
+
+// Sentinel errors returned by the repository and service layers.
+// Callers should compare with errors.Is since they are usually wrapped.
+var (
+	// ErrRoleNotFound is returned when no matching role exists
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleExists is returned when a role name is already taken
+	ErrRoleExists = errors.New("role already exists")
+
+	// ErrSystemRole is returned when renaming or deleting a built-in role
+	ErrSystemRole = errors.New("system roles cannot be changed")
+
+	// ErrUnknownPermission is returned when a role references an undefined permission
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	// ErrUserNotFound is returned when assigning a role to a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+)
@@ -0,0 +1,234 @@
+package roles
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// ListRoles handles GET /roles
+func (h *handler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list roles", "error", err)
+		problem.Internal(c, "Failed to fetch roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// GetRole handles GET /roles/:id
+func (h *handler) GetRole(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	role, err := h.service.GetRole(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to get role", "error", err, "id", id)
+		respondError(c, err, "Failed to get role")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// CreateRole handles POST /roles
+func (h *handler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), req)
+	if err != nil {
+		slog.Error("Failed to create role", "error", err)
+		respondError(c, err, "Failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole handles PATCH /roles/:id
+func (h *handler) UpdateRole(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), id, req)
+	if err != nil {
+		slog.Error("Failed to update role", "error", err, "id", id)
+		respondError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole handles DELETE /roles/:id
+func (h *handler) DeleteRole(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRole(c.Request.Context(), id); err != nil {
+		slog.Error("Failed to delete role", "error", err, "id", id)
+		respondError(c, err, "Failed to delete role")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListPermissions handles GET /roles/permissions
+func (h *handler) ListPermissions(c *gin.Context) {
+	permissions, err := h.service.ListPermissions(c.Request.Context())
+	if err != nil {
+		slog.Error("Failed to list permissions", "error", err)
+		problem.Internal(c, "Failed to fetch permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": permissions})
+}
+
+// SetRolePermissions handles PUT /roles/:id/permissions
+func (h *handler) SetRolePermissions(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req SetPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	role, err := h.service.SetRolePermissions(c.Request.Context(), id, req)
+	if err != nil {
+		slog.Error("Failed to set role permissions", "error", err, "id", id)
+		respondError(c, err, "Failed to set role permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// AssignRole handles PUT /roles/:id/users/:user_id
+func (h *handler) AssignRole(c *gin.Context) {
+	roleID, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+	userID, ok := parseID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.AssignRole(c.Request.Context(), roleID, userID); err != nil {
+		slog.Error("Failed to assign role", "error", err, "role_id", roleID, "user_id", userID)
+		respondError(c, err, "Failed to assign role")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeRole handles DELETE /roles/:id/users/:user_id
+func (h *handler) RevokeRole(c *gin.Context) {
+	roleID, ok := parseID(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+	userID, ok := parseID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeRole(c.Request.Context(), roleID, userID); err != nil {
+		slog.Error("Failed to revoke role", "error", err, "role_id", roleID, "user_id", userID)
+		respondError(c, err, "Failed to revoke role")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserRoles handles GET /roles/users/:user_id
+func (h *handler) ListUserRoles(c *gin.Context) {
+	userID, ok := parseID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	roles, err := h.service.ListUserRoles(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to list user roles", "error", err, "user_id", userID)
+		respondError(c, err, "Failed to list user roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// parseID reads a UUID path parameter, writing a 400 problem if it is invalid
+func parseID(c *gin.Context, param, detail string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		problem.BadRequest(c, detail)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondError maps domain errors to problem responses.
+// Unknown errors are reported as 500 with the given fallback detail.
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		problem.Abort(c, http.StatusNotFound, problem.TypeNotFound, "Role not found")
+	case errors.Is(err, ErrUserNotFound):
+		problem.Abort(c, http.StatusNotFound, problem.TypeNotFound, "User not found")
+	case errors.Is(err, ErrRoleExists):
+		p := problem.New(http.StatusConflict, problem.TypeConflict, "Role name already in use")
+		p.Errors = []problem.FieldError{{Field: "name", Rule: "unique", Message: "is already in use"}}
+		problem.Write(c, p)
+	case errors.Is(err, ErrSystemRole):
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "System roles cannot be renamed or deleted")
+	case errors.Is(err, ErrUnknownPermission):
+		problem.Validation(c, "Unknown permission", []problem.FieldError{{
+			Field:   "permissions",
+			Rule:    "exists",
+			Message: err.Error(),
+		}})
+	default:
+		problem.Internal(c, fallback)
+	}
+}
@@ -0,0 +1,39 @@
+package roles
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission only lets callers through who hold every listed
+// permission. The caller comes from middleware.IdentityContext; requests
+// without one get 401, callers lacking a permission get 403.
+func RequirePermission(authz Authorizer, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, ok := middleware.IdentityFrom(c.Request.Context())
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, problem.TypeUnauthorized, "Authentication required")
+			return
+		}
+		callerID := caller.UserID
+
+		for _, permission := range permissions {
+			allowed, err := authz.HasPermission(c.Request.Context(), callerID, permission)
+			if err != nil {
+				slog.Error("Failed to check permission", "error", err, "caller_id", callerID, "permission", permission)
+				problem.Internal(c, "Failed to check permissions")
+				return
+			}
+			if !allowed {
+				problem.Abort(c, http.StatusForbidden, problem.TypeForbidden, "Missing permission "+permission)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package roles
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions checked by the Go services. New permissions are added to this
+// list and seeded by a migration.
+const (
+	PermUsersRead  = "users:read"  // list and view any user
+	PermUsersAdmin = "users:admin" // reset passwords, suspend, restore and unlock users
+	PermRolesRead  = "roles:read"  // list roles, permissions and assignments
+	PermRolesWrite = "roles:write" // create, change and assign roles
+	PermAuditRead  = "audit:read"  // view the audit log
+)
+
+// Role is a named set of permissions
+type Role struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	System      bool      `json:"system"` // built-in, cannot be renamed or deleted
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permission is a single capability a role can grant
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateRoleRequest represents the request to create a role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=64"`
+	Description *string  `json:"description" binding:"omitnil,max=255"`
+	Permissions []string `json:"permissions" binding:"omitempty,dive,required,max=64"`
+}
+
+// UpdateRoleRequest represents the request to update a role
+type UpdateRoleRequest struct {
+	Name        *string `json:"name" binding:"omitnil,min=2,max=64"`
+	Description *string `json:"description" binding:"omitnil,max=255"`
+}
+
+// SetPermissionsRequest replaces every permission granted by a role
+type SetPermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required,dive,required,max=64"`
+}
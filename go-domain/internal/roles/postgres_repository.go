@@ -0,0 +1,304 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SQLSTATEs mapped to domain errors
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// roleSelect reads roles with their permissions aggregated, in scanRole order
+const roleSelect = `
+	SELECT r.id, r.name, r.description, r.system, r.created_at, r.updated_at,
+	       COALESCE(array_agg(rp.permission ORDER BY rp.permission)
+	                FILTER (WHERE rp.permission IS NOT NULL), '{}')
+	FROM roles r
+	LEFT JOIN role_permissions rp ON rp.role_id = r.id
+`
+
+// postgresRepository implements the Repository interface using PostgreSQL
+type postgresRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *pgxpool.Pool) Repository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+// Create inserts a role and its permissions in one transaction
+func (r *postgresRepository) Create(ctx context.Context, role *Role) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO roles (name, description)
+			VALUES ($1, $2)
+			RETURNING id, system, created_at, updated_at
+		`
+
+		err := tx.QueryRow(ctx, query, role.Name, role.Description).
+			Scan(&role.ID, &role.System, &role.CreatedAt, &role.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create role: %w", mapWriteError(err))
+		}
+
+		return grantPermissions(ctx, tx, role.ID, role.Permissions)
+	})
+}
+
+// GetByID retrieves a role by its ID
+func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Role, error) {
+	query := roleSelect + ` WHERE r.id = $1 GROUP BY r.id`
+
+	role, err := scanRole(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return role, nil
+}
+
+// List retrieves all roles ordered by name
+func (r *postgresRepository) List(ctx context.Context) ([]*Role, error) {
+	return r.queryRoles(ctx, roleSelect+` GROUP BY r.id ORDER BY r.name`)
+}
+
+// Update stores a role's name and description
+func (r *postgresRepository) Update(ctx context.Context, role *Role) error {
+	query := `
+		UPDATE roles
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, role.Name, role.Description, role.ID).Scan(&role.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", mapWriteError(err))
+	}
+
+	return nil
+}
+
+// Delete deletes a role; its grants and assignments cascade
+func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// SetPermissions replaces a role's permissions in one transaction
+func (r *postgresRepository) SetPermissions(ctx context.Context, roleID uuid.UUID, permissions []string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Lock the role so concurrent replacements do not interleave
+		var id uuid.UUID
+		err := tx.QueryRow(ctx, `SELECT id FROM roles WHERE id = $1 FOR UPDATE`, roleID).Scan(&id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrRoleNotFound
+			}
+			return fmt.Errorf("failed to lock role: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+			return fmt.Errorf("failed to clear permissions: %w", err)
+		}
+		if err := grantPermissions(ctx, tx, roleID, permissions); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE roles SET updated_at = NOW() WHERE id = $1`, roleID)
+		if err != nil {
+			return fmt.Errorf("failed to touch role: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListPermissions retrieves every defined permission ordered by name
+func (r *postgresRepository) ListPermissions(ctx context.Context) ([]Permission, error) {
+	rows, err := r.db.Query(ctx, `SELECT name, description FROM permissions ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []Permission
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// AssignRole grants a role to a user
+func (r *postgresRepository) AssignRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID, roleID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			if pgErr.ConstraintName == "user_roles_role_id_fkey" {
+				return ErrRoleNotFound
+			}
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRole removes a role from a user. Returns ErrRoleNotFound if the
+// user did not have it.
+func (r *postgresRepository) RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// ListUserRoles retrieves the roles assigned to a user ordered by name
+func (r *postgresRepository) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*Role, error) {
+	query := roleSelect + `
+		JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		GROUP BY r.id
+		ORDER BY r.name
+	`
+	return r.queryRoles(ctx, query, userID)
+}
+
+// UserHasPermission reports whether an active user holds permission through any role
+func (r *postgresRepository) UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_roles ur
+			JOIN role_permissions rp ON rp.role_id = ur.role_id
+			JOIN users u ON u.id = ur.user_id
+			WHERE ur.user_id = $1 AND rp.permission = $2 AND u.status = 'active'
+		)
+	`
+
+	var allowed bool
+	if err := r.db.QueryRow(ctx, query, userID, permission).Scan(&allowed); err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return allowed, nil
+}
+
+// queryRoles runs a roleSelect query returning many roles
+func (r *postgresRepository) queryRoles(ctx context.Context, query string, args ...any) ([]*Role, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []*Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// scanRole scans a row selected with roleSelect
+func scanRole(row pgx.Row) (*Role, error) {
+	role := &Role{}
+	err := row.Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.System,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+		&role.Permissions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// grantPermissions adds permissions to a role inside tx
+func grantPermissions(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::varchar[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, roleID, permissions); err != nil {
+		return fmt.Errorf("failed to grant permissions: %w", mapWriteError(err))
+	}
+
+	return nil
+}
+
+// mapWriteError translates known PostgreSQL errors into domain errors
+func mapWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return ErrRoleExists
+		case pgForeignKeyViolation:
+			return ErrUnknownPermission
+		}
+	}
+	return err
+}
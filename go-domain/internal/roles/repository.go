@@ -0,0 +1,44 @@
+package roles
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines the interface for role data operations
+type Repository interface {
+	// Create creates a role and grants its permissions.
+	// Returns ErrRoleExists or ErrUnknownPermission.
+	Create(ctx context.Context, role *Role) error
+
+	// GetByID retrieves a role with its permissions
+	GetByID(ctx context.Context, id uuid.UUID) (*Role, error)
+
+	// List retrieves all roles ordered by name
+	List(ctx context.Context) ([]*Role, error)
+
+	// Update stores a role's name and description
+	Update(ctx context.Context, role *Role) error
+
+	// Delete deletes a role and its assignments
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// SetPermissions replaces every permission granted by a role
+	SetPermissions(ctx context.Context, roleID uuid.UUID, permissions []string) error
+
+	// ListPermissions retrieves every defined permission
+	ListPermissions(ctx context.Context) ([]Permission, error)
+
+	// AssignRole grants a role to a user. Assigning it twice is not an error.
+	AssignRole(ctx context.Context, userID, roleID uuid.UUID) error
+
+	// RevokeRole removes a role from a user
+	RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error
+
+	// ListUserRoles retrieves the roles assigned to a user
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*Role, error)
+
+	// UserHasPermission reports whether any of the user's roles grants permission
+	UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+}
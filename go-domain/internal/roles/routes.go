@@ -0,0 +1,32 @@
+package roles
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers all role-related routes. The service is also the
+// Authorizer used to guard other domains' routes.
+func RegisterRoutes(router *gin.RouterGroup, service Service) {
+	handler := NewHandler(service)
+
+	canRead := RequirePermission(service, PermRolesRead)
+	canWrite := RequirePermission(service, PermRolesWrite)
+
+	roles := router.Group("/roles")
+	{
+		roles.GET("", canRead, handler.ListRoles)                   // GET /api/v1/roles
+		roles.GET("/:id", canRead, handler.GetRole)                 // GET /api/v1/roles/:id
+		roles.POST("", canWrite, handler.CreateRole)                // POST /api/v1/roles
+		roles.PATCH("/:id", canWrite, handler.UpdateRole)           // PATCH /api/v1/roles/:id
+		roles.DELETE("/:id", canWrite, handler.DeleteRole)          // DELETE /api/v1/roles/:id
+		roles.GET("/permissions", canRead, handler.ListPermissions) // GET /api/v1/roles/permissions
+
+		// Permissions granted by a role
+		roles.PUT("/:id/permissions", canWrite, handler.SetRolePermissions) // PUT /api/v1/roles/:id/permissions
+
+		// Assignments
+		roles.PUT("/:id/users/:user_id", canWrite, handler.AssignRole)    // PUT /api/v1/roles/:id/users/:user_id
+		roles.DELETE("/:id/users/:user_id", canWrite, handler.RevokeRole) // DELETE /api/v1/roles/:id/users/:user_id
+		roles.GET("/users/:user_id", canRead, handler.ListUserRoles)      // GET /api/v1/roles/users/:user_id
+	}
+}
@@ -0,0 +1,213 @@
+package roles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Authorizer answers permission checks for a calling user. Other domains
+// depend on this instead of the full Service.
+type Authorizer interface {
+	HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+}
+
+type Service interface {
+	Authorizer
+
+	// Role CRUD operations
+	CreateRole(ctx context.Context, req CreateRoleRequest) (*Role, error)
+	GetRole(ctx context.Context, id uuid.UUID) (*Role, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, req UpdateRoleRequest) (*Role, error)
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+
+	// Permissions
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	SetRolePermissions(ctx context.Context, id uuid.UUID, req SetPermissionsRequest) (*Role, error)
+
+	// Assignments
+	AssignRole(ctx context.Context, roleID, userID uuid.UUID) error
+	RevokeRole(ctx context.Context, roleID, userID uuid.UUID) error
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*Role, error)
+}
+
+type svc struct {
+	repo Repository
+}
+
+// NewService creates a new role service
+func NewService(repo Repository) Service {
+	return &svc{
+		repo: repo,
+	}
+}
+
+// HasPermission reports whether the user holds permission through any role
+func (s *svc) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	allowed, err := s.repo.UserHasPermission(ctx, userID, permission)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	return allowed, nil
+}
+
+// CreateRole creates a custom role with the given permissions
+func (s *svc) CreateRole(ctx context.Context, req CreateRoleRequest) (*Role, error) {
+	if err := s.checkPermissions(ctx, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	role := &Role{
+		Name:        normalizeName(req.Name),
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetRole retrieves a role by ID
+func (s *svc) GetRole(ctx context.Context, id uuid.UUID) (*Role, error) {
+	role, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// ListRoles retrieves all roles
+func (s *svc) ListRoles(ctx context.Context) ([]*Role, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// UpdateRole changes a role's name or description. System roles keep their name.
+func (s *svc) UpdateRole(ctx context.Context, id uuid.UUID, req UpdateRoleRequest) (*Role, error) {
+	role, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if req.Name != nil {
+		name := normalizeName(*req.Name)
+		if role.System && name != role.Name {
+			return nil, ErrSystemRole
+		}
+		role.Name = name
+	}
+	if req.Description != nil {
+		role.Description = req.Description
+	}
+
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes a custom role and removes it from every user
+func (s *svc) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	role, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+	if role.System {
+		return ErrSystemRole
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return nil
+}
+
+// ListPermissions retrieves every permission a role can grant
+func (s *svc) ListPermissions(ctx context.Context) ([]Permission, error) {
+	permissions, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// SetRolePermissions replaces the permissions a role grants
+func (s *svc) SetRolePermissions(ctx context.Context, id uuid.UUID, req SetPermissionsRequest) (*Role, error) {
+	if err := s.checkPermissions(ctx, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPermissions(ctx, id, req.Permissions); err != nil {
+		return nil, fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	return s.GetRole(ctx, id)
+}
+
+// AssignRole grants a role to a user
+func (s *svc) AssignRole(ctx context.Context, roleID, userID uuid.UUID) error {
+	if err := s.repo.AssignRole(ctx, userID, roleID); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+	return nil
+}
+
+// RevokeRole removes a role from a user
+func (s *svc) RevokeRole(ctx context.Context, roleID, userID uuid.UUID) error {
+	if err := s.repo.RevokeRole(ctx, userID, roleID); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	return nil
+}
+
+// ListUserRoles retrieves the roles assigned to a user
+func (s *svc) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*Role, error) {
+	roles, err := s.repo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// checkPermissions rejects names that are not defined, naming the first one
+func (s *svc) checkPermissions(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	defined, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	known := make(map[string]struct{}, len(defined))
+	for _, p := range defined {
+		known[p.Name] = struct{}{}
+	}
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownPermission, name)
+		}
+	}
+
+	return nil
+}
+
+// normalizeName trims and lowercases role names; they are matched case-insensitively
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,118 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Authenticate verifies a username-or-email and password pair. Users with
+// a second factor get an MFA challenge to finish the login with VerifyMFA.
+// Every failure to match returns ErrInvalidCredentials after a full hash
+// comparison. Suspended accounts are only reported once the password matched.
+//
+// Failed attempts are throttled per account and per clientIP. A locked
+// clientIP gets a *LockedError; a locked account is answered exactly like an
+// unknown login, so lockouts cannot be used to tell which accounts exist.
+func (s *svc) Authenticate(ctx context.Context, req AuthenticateRequest, clientIP string) (*AuthenticateResponse, error) {
+	if clientIP != "" {
+		if err := s.checkLocked(ctx, throttleIP, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.findByLogin(ctx, req.Login)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			// Spend the same hashing time as a real check
+			s.passwordMatches(&User{PasswordHash: s.dummyHash()}, req.Password)
+			s.recordIPFailure(ctx, clientIP)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	if err := s.checkLocked(ctx, throttleUser, user.ID.String()); err != nil {
+		var locked *LockedError
+		if !errors.As(err, &locked) {
+			return nil, err
+		}
+		s.passwordMatches(&User{PasswordHash: s.dummyHash()}, req.Password)
+		s.recordIPFailure(ctx, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+
+	match, needsRehash := s.passwordMatches(user, req.Password)
+	if !match {
+		s.recordLoginFailure(ctx, throttleUser, user.ID.String(), s.lockout.UserThreshold, &user.ID, clientIP)
+		s.recordIPFailure(ctx, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Status != StatusActive {
+		return nil, ErrAccountInactive
+	}
+
+	// Upgrade legacy or outdated hashes while the plaintext is at hand.
+	// A rehash is not a password change, so sessions stay valid.
+	if needsRehash {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	// With a second factor the login only completes in VerifyMFA. Failures
+	// are kept until then, so a known password cannot reset the lockout
+	// backoff between rounds of code guesses.
+	if user.MFAEnabledAt != nil {
+		challenge, err := s.issueMFAChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &AuthenticateResponse{MFARequired: true, MFAChallenge: challenge}, nil
+	}
+
+	if err := s.completeLogin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &AuthenticateResponse{User: &response}, nil
+}
+
+// completeLogin forgives the account's earlier failures and records the
+// login, once every factor has passed
+func (s *svc) completeLogin(ctx context.Context, user *User) error {
+	if err := s.repo.ClearLoginThrottle(ctx, throttleUser, user.ID.String(), nil); err != nil {
+		slog.ErrorContext(ctx, "Failed to clear login throttle", "error", err, "user_id", user.ID)
+	}
+
+	if err := s.repo.RecordLogin(ctx, user); err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+	return nil
+}
+
+// findByLogin resolves a login to a user. Usernames cannot contain '@'
+// (see validateUsername), so anything with one is treated as an email.
+func (s *svc) findByLogin(ctx context.Context, login string) (*User, error) {
+	if strings.Contains(login, "@") {
+		return s.repo.GetByEmail(ctx, normalizeEmail(login))
+	}
+	return s.repo.GetByUsername(ctx, normalizeUsername(login))
+}
+
+// rehashPassword replaces the user's hash using the current hasher settings.
+// Failures are logged only; the old hash keeps working.
+func (s *svc) rehashPassword(ctx context.Context, user *User, password string) {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to rehash password", "error", err, "user_id", user.ID)
+		return
+	}
+	if err := s.repo.ReplacePasswordHash(ctx, user.ID, hash); err != nil {
+		slog.ErrorContext(ctx, "Failed to store rehashed password", "error", err, "user_id", user.ID)
+		return
+	}
+	user.PasswordHash = hash
+}
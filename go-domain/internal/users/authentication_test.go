@@ -0,0 +1,302 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginRepo is an in-memory Repository for the login flow. Methods the flow
+// does not use panic through the nil embedded interface.
+type loginRepo struct {
+	Repository
+	users     map[string]*User // by username
+	throttles map[string]*LoginThrottle
+	cleared   []string             // subjects passed to ClearLoginThrottle
+	logins    int                  // calls to RecordLogin
+	rehashed  map[uuid.UUID]string // hashes passed to ReplacePasswordHash
+
+	totp       map[uuid.UUID]*TOTPCredential
+	challenges map[string]uuid.UUID // by challenge hash
+	used       map[string]bool      // consumed challenge hashes
+}
+
+func newLoginRepo(users ...*User) *loginRepo {
+	repo := &loginRepo{
+		users:      map[string]*User{},
+		throttles:  map[string]*LoginThrottle{},
+		rehashed:   map[uuid.UUID]string{},
+		totp:       map[uuid.UUID]*TOTPCredential{},
+		challenges: map[string]uuid.UUID{},
+		used:       map[string]bool{},
+	}
+	for _, user := range users {
+		repo.users[user.Username] = user
+	}
+	return repo
+}
+
+func (r *loginRepo) GetByUsername(ctx context.Context, username string) (*User, error) {
+	user, ok := r.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *loginRepo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *loginRepo) GetLoginThrottle(ctx context.Context, subjectType, subject string) (*LoginThrottle, error) {
+	if throttle, ok := r.throttles[subjectType+":"+subject]; ok {
+		return throttle, nil
+	}
+	return &LoginThrottle{SubjectType: subjectType, Subject: subject}, nil
+}
+
+func (r *loginRepo) RecordLoginFailure(ctx context.Context, subjectType, subject string, window time.Duration) (*LoginThrottle, error) {
+	throttle, _ := r.GetLoginThrottle(ctx, subjectType, subject)
+	throttle.FailedAttempts++
+	r.throttles[subjectType+":"+subject] = throttle
+	return throttle, nil
+}
+
+func (r *loginRepo) LockLoginSubject(ctx context.Context, event LockoutEvent) error {
+	throttle := r.throttles[event.SubjectType+":"+event.Subject]
+	throttle.LockedUntil = event.LockedUntil
+	throttle.Lockouts++
+	throttle.FailedAttempts = 0
+	return nil
+}
+
+func (r *loginRepo) ClearLoginThrottle(ctx context.Context, subjectType, subject string, event *LockoutEvent) error {
+	delete(r.throttles, subjectType+":"+subject)
+	r.cleared = append(r.cleared, subject)
+	return nil
+}
+
+func (r *loginRepo) RecordLogin(ctx context.Context, user *User) error {
+	r.logins++
+	return nil
+}
+
+func (r *loginRepo) ReplacePasswordHash(ctx context.Context, id uuid.UUID, hash string) error {
+	r.rehashed[id] = hash
+	return nil
+}
+
+func (r *loginRepo) GetTOTP(ctx context.Context, userID uuid.UUID) (*TOTPCredential, error) {
+	credential, ok := r.totp[userID]
+	if !ok {
+		return nil, ErrMFANotEnrolled
+	}
+	return credential, nil
+}
+
+func (r *loginRepo) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	credential := r.totp[userID]
+	if credential.LastUsedStep != nil && step <= *credential.LastUsedStep {
+		return ErrInvalidMFACode
+	}
+	credential.LastUsedStep = &step
+	return nil
+}
+
+func (r *loginRepo) CreateMFAChallenge(ctx context.Context, userID uuid.UUID, challengeHash []byte, expiresAt time.Time) error {
+	r.challenges[string(challengeHash)] = userID
+	return nil
+}
+
+func (r *loginRepo) GetMFAChallengeUser(ctx context.Context, challengeHash []byte) (uuid.UUID, error) {
+	userID, ok := r.challenges[string(challengeHash)]
+	if !ok || r.used[string(challengeHash)] {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func (r *loginRepo) ConsumeMFAChallenge(ctx context.Context, challengeHash []byte) (uuid.UUID, error) {
+	userID, err := r.GetMFAChallengeUser(ctx, challengeHash)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	r.used[string(challengeHash)] = true
+	return userID, nil
+}
+
+// testArgon2Params keeps hashing fast in tests
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// newLoginService returns a service over repo whose users all have password
+func newLoginService(t *testing.T, repo *loginRepo, password string) *svc {
+	t.Helper()
+	hasher := NewPasswordHasher(testArgon2Params)
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	for _, user := range repo.users {
+		user.PasswordHash = hash
+	}
+	totp := DefaultTOTPConfig()
+	totp.EncryptionKey = make([]byte, 32)
+	return NewService(repo, NewLogPublisher(), hasher, NewPasswordPolicy(DefaultPasswordPolicyConfig()), DefaultLockoutConfig(), totp, nil).(*svc)
+}
+
+func TestAuthenticateCompletesLoginOnlyWithoutSecondFactor(t *testing.T) {
+	enabled := time.Now()
+
+	tests := []struct {
+		name         string
+		mfaEnabledAt *time.Time
+		wantComplete bool
+	}{
+		{name: "password only", mfaEnabledAt: nil, wantComplete: true},
+		{name: "second factor pending", mfaEnabledAt: &enabled, wantComplete: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &User{ID: uuid.New(), Username: "alice", Status: StatusActive, MFAEnabledAt: tt.mfaEnabledAt}
+			repo := newLoginRepo(user)
+			s := newLoginService(t, repo, "correct horse")
+
+			// An earlier wrong guess leaves a failure on the account
+			if _, err := s.Authenticate(context.Background(), AuthenticateRequest{Login: "alice", Password: "wrong"}, ""); err == nil {
+				t.Fatal("Authenticate() with a wrong password succeeded")
+			}
+
+			if _, err := s.Authenticate(context.Background(), AuthenticateRequest{Login: "alice", Password: "correct horse"}, ""); err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+
+			_, throttled := repo.throttles[throttleUser+":"+user.ID.String()]
+			if throttled == tt.wantComplete {
+				t.Errorf("account throttle kept = %v, want %v", throttled, !tt.wantComplete)
+			}
+			if completed := repo.logins == 1; completed != tt.wantComplete {
+				t.Errorf("login recorded = %v, want %v", completed, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRehashesOutdatedHashes(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		stored     string // replaces the current hash when set
+		password   string
+		wantRehash bool
+	}{
+		{name: "current hash", password: "correct horse"},
+		{name: "bcrypt hash", stored: string(legacy), password: "correct horse", wantRehash: true},
+		{name: "bcrypt hash wrong password", stored: string(legacy), password: "wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &User{ID: uuid.New(), Username: "alice", Status: StatusActive}
+			repo := newLoginRepo(user)
+			s := newLoginService(t, repo, "correct horse")
+			if tt.stored != "" {
+				user.PasswordHash = tt.stored
+			}
+
+			_, _ = s.Authenticate(context.Background(), AuthenticateRequest{Login: "alice", Password: tt.password}, "")
+
+			hash, rehashed := repo.rehashed[user.ID]
+			if rehashed != tt.wantRehash {
+				t.Fatalf("rehashed = %v, want %v", rehashed, tt.wantRehash)
+			}
+			if !rehashed {
+				return
+			}
+			match, needsRehash, err := s.hasher.Verify(tt.password, hash)
+			if !match || needsRehash || err != nil {
+				t.Errorf("Verify(new hash) = %v, %v, %v, want a current argon2id hash of the password", match, needsRehash, err)
+			}
+		})
+	}
+}
+
+func TestVerifyMFA(t *testing.T) {
+	enabled := time.Now()
+	user := &User{ID: uuid.New(), Username: "alice", Status: StatusActive, MFAEnabledAt: &enabled}
+	repo := newLoginRepo(user)
+	s := newLoginService(t, repo, "correct horse")
+
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := s.secrets.seal(secret, user.ID[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.totp[user.ID] = &TOTPCredential{UserID: user.ID, SecretCiphertext: ciphertext, ConfirmedAt: &enabled}
+
+	ctx := context.Background()
+	login, err := s.Authenticate(ctx, AuthenticateRequest{Login: "alice", Password: "correct horse"}, "")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if !login.MFARequired || login.MFAChallenge == "" || login.User != nil {
+		t.Fatalf("Authenticate() = %+v, want only an MFA challenge", login)
+	}
+
+	wrongCode := "000000"
+	if wrongCode == totpCode(secret, totpStep(time.Now())) {
+		wrongCode = "111111"
+	}
+	subject := throttleUser + ":" + user.ID.String()
+
+	steps := []struct {
+		name         string
+		challenge    string
+		code         string
+		wantErr      error
+		wantFailures int
+	}{
+		{name: "unknown challenge", challenge: "forged", code: totpCode(secret, totpStep(time.Now())), wantErr: ErrInvalidToken},
+		{name: "wrong code", challenge: login.MFAChallenge, code: wrongCode, wantErr: ErrInvalidMFACode, wantFailures: 1},
+		{name: "current code", challenge: login.MFAChallenge, code: totpCode(secret, totpStep(time.Now()))},
+		{name: "challenge reused", challenge: login.MFAChallenge, code: totpCode(secret, totpStep(time.Now())+1), wantErr: ErrInvalidToken},
+	}
+
+	for _, step := range steps {
+		result, err := s.VerifyMFA(ctx, VerifyMFARequest{Challenge: step.challenge, Code: step.code}, "")
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: VerifyMFA() error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err != nil {
+			if throttle := repo.throttles[subject]; step.wantFailures > 0 && (throttle == nil || throttle.FailedAttempts != step.wantFailures) {
+				t.Errorf("%s: account throttle = %+v, want %d failures", step.name, throttle, step.wantFailures)
+			}
+			continue
+		}
+		if result.User == nil || result.User.ID != user.ID {
+			t.Errorf("%s: VerifyMFA() user = %+v, want %s", step.name, result.User, user.ID)
+		}
+		if _, throttled := repo.throttles[subject]; throttled || repo.logins != 1 {
+			t.Errorf("%s: throttle kept = %v, logins = %d, want the login completed", step.name, throttled, repo.logins)
+		}
+	}
+}
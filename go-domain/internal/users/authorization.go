@@ -0,0 +1,77 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
+	"github.com/google/uuid"
+)
+
+// authorizeChange decides whether the caller may modify the target user.
+// Callers may change their own profile; changing another user or any of the
+// privileged fields (json names) requires the users:admin permission.
+// It runs before the target is loaded so denials do not reveal which ids exist.
+func (s *svc) authorizeChange(ctx context.Context, targetID uuid.UUID, privileged ...string) error {
+	caller, ok := middleware.IdentityFrom(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if caller.UserID == targetID && len(privileged) == 0 {
+		return nil
+	}
+
+	isAdmin, err := s.authz.HasPermission(ctx, caller.UserID, roles.PermUsersAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if isAdmin {
+		return nil
+	}
+
+	if caller.UserID != targetID {
+		return &ForbiddenError{Reason: "You can only modify your own account"}
+	}
+	return &ForbiddenError{
+		Reason: fmt.Sprintf("Changing %s requires the %s permission", strings.Join(privileged, ", "), roles.PermUsersAdmin),
+	}
+}
+
+// authorizeOwner allows only the owner of the target account. It guards
+// credentials, which not even an admin may use on someone else's behalf.
+func authorizeOwner(ctx context.Context, targetID uuid.UUID) error {
+	caller, ok := middleware.IdentityFrom(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if caller.UserID != targetID {
+		return &ForbiddenError{Reason: "You can only manage the credentials of your own account"}
+	}
+	return nil
+}
+
+// authorizeRead decides whether the caller may read the target user or its
+// history. Callers may read their own; anyone else's requires users:read.
+func (s *svc) authorizeRead(ctx context.Context, targetID uuid.UUID) error {
+	caller, ok := middleware.IdentityFrom(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if caller.UserID == targetID {
+		return nil
+	}
+
+	canRead, err := s.authz.HasPermission(ctx, caller.UserID, roles.PermUsersRead)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !canRead {
+		return &ForbiddenError{Reason: "You can only read your own account"}
+	}
+
+	return nil
+}
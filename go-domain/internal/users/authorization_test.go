@@ -0,0 +1,70 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/middleware"
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
+	"github.com/google/uuid"
+)
+
+// grants is a roles.Authorizer holding the caller's permissions
+type grants []string
+
+func (g grants) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	for _, granted := range g {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestAuthorization(t *testing.T) {
+	self, other := uuid.New(), uuid.New()
+	signedIn := middleware.WithIdentity(context.Background(), middleware.Identity{UserID: self})
+	anonymous := context.Background()
+
+	change := func(privileged ...string) func(*svc, context.Context, uuid.UUID) error {
+		return func(s *svc, ctx context.Context, id uuid.UUID) error {
+			return s.authorizeChange(ctx, id, privileged...)
+		}
+	}
+	read := func(s *svc, ctx context.Context, id uuid.UUID) error { return s.authorizeRead(ctx, id) }
+	owner := func(s *svc, ctx context.Context, id uuid.UUID) error { return authorizeOwner(ctx, id) }
+
+	tests := []struct {
+		name      string
+		authorize func(*svc, context.Context, uuid.UUID) error
+		ctx       context.Context
+		target    uuid.UUID
+		grants    grants
+		wantErr   error
+	}{
+		{name: "change own profile", authorize: change(), ctx: signedIn, target: self},
+		{name: "change own privileged field", authorize: change("is_active"), ctx: signedIn, target: self, wantErr: ErrForbidden},
+		{name: "change own privileged field as admin", authorize: change("is_active"), ctx: signedIn, target: self, grants: grants{roles.PermUsersAdmin}},
+		{name: "change another user", authorize: change(), ctx: signedIn, target: other, wantErr: ErrForbidden},
+		{name: "change another user with read only", authorize: change(), ctx: signedIn, target: other, grants: grants{roles.PermUsersRead}, wantErr: ErrForbidden},
+		{name: "change another user as admin", authorize: change(), ctx: signedIn, target: other, grants: grants{roles.PermUsersAdmin}},
+		{name: "change anonymously", authorize: change(), ctx: anonymous, target: self, wantErr: ErrUnauthenticated},
+		{name: "read own account", authorize: read, ctx: signedIn, target: self},
+		{name: "read another user", authorize: read, ctx: signedIn, target: other, wantErr: ErrForbidden},
+		{name: "read another user with users:read", authorize: read, ctx: signedIn, target: other, grants: grants{roles.PermUsersRead}},
+		{name: "read anonymously", authorize: read, ctx: anonymous, target: self, wantErr: ErrUnauthenticated},
+		{name: "own credentials", authorize: owner, ctx: signedIn, target: self},
+		{name: "another user's credentials as admin", authorize: owner, ctx: signedIn, target: other, grants: grants{roles.PermUsersAdmin}, wantErr: ErrForbidden},
+		{name: "credentials anonymously", authorize: owner, ctx: anonymous, target: self, wantErr: ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &svc{authz: tt.grants}
+			if err := tt.authorize(s, tt.ctx, tt.target); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package users
+
+// Config holds settings for the users domain
+type Config struct {
+	Argon2         Argon2Params         // password hashing cost for new hashes
+	PasswordPolicy PasswordPolicyConfig // rules for new passwords
+	Lockout        LockoutConfig        // failed login throttling
+	TOTP           TOTPConfig           // second factor settings
+}
+
+// DefaultConfig returns a users configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		Argon2:         DefaultArgon2Params(),
+		PasswordPolicy: DefaultPasswordPolicyConfig(),
+		Lockout:        DefaultLockoutConfig(),
+		TOTP:           DefaultTOTPConfig(),
+	}
+}
@@ -0,0 +1,66 @@
+package users
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Page sizes for user listings
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// Cursor is a position in a user listing: the sort value and id of a user.
+// Forward cursors select the users after that position in the listing's
+// order, backward cursors the users before it.
+type Cursor struct {
+	Sort     Sort      `json:"s"`
+	Value    string    `json:"v"` // see sortValue
+	ID       uuid.UUID `json:"id"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// pageLimit clamps a requested page size to (0, MaxPageSize], using the
+// default for missing or invalid sizes
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+// encodeCursor returns the opaque token clients pass back in ?cursor=
+func encodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c) // cannot fail for this type
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token produced by encodeCursor for a listing sorted by sort
+func decodeCursor(token string, sort Sort) (Cursor, error) {
+	invalid := &ValidationError{Field: "cursor", Rule: "cursor", Message: "is not a valid page cursor"}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, invalid
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return Cursor{}, invalid
+	}
+	if _, err := parseSortValue(c.Sort.Field, c.Value); err != nil {
+		return Cursor{}, invalid
+	}
+	if c.Sort != sort {
+		return Cursor{}, &ValidationError{Field: "cursor", Rule: "cursor", Message: "was issued for sort " + c.Sort.String()}
+	}
+	return c, nil
+}
+
+// cursorAt returns a cursor positioned at user, pointing in the given direction
+func cursorAt(user *User, sort Sort, backward bool) string {
+	return encodeCursor(Cursor{Sort: sort, Value: sortValue(user, sort.Field), ID: user.ID, Backward: backward})
+}
@@ -0,0 +1,78 @@
+package users
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	id := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	valid := Cursor{Sort: DefaultSort, Value: "2024-05-01T10:00:00.123456Z", ID: id}
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name        string
+		token       string
+		sort        Sort
+		want        Cursor
+		wantMessage string // empty when the cursor is valid
+	}{
+		{name: "round trip", token: encodeCursor(valid), sort: DefaultSort, want: valid},
+		{
+			name:  "backward",
+			token: encodeCursor(Cursor{Sort: Sort{Field: SortUsername}, Value: "alice", ID: id, Backward: true}),
+			sort:  Sort{Field: SortUsername},
+			want:  Cursor{Sort: Sort{Field: SortUsername}, Value: "alice", ID: id, Backward: true},
+		},
+		{name: "not base64", token: "!!!", sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte(`{"s":{"f":"created_at"}}`)), sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "not json", token: raw("created_at"), sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "missing id", token: encodeCursor(Cursor{Sort: DefaultSort, Value: valid.Value}), sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "bad timestamp", token: encodeCursor(Cursor{Sort: DefaultSort, Value: "yesterday", ID: id}), sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "unsortable field", token: encodeCursor(Cursor{Sort: Sort{Field: "password_hash"}, Value: "x", ID: id}), sort: DefaultSort, wantMessage: "is not a valid page cursor"},
+		{name: "other sort", token: encodeCursor(valid), sort: Sort{Field: SortCreatedAt}, wantMessage: "was issued for sort " + DefaultSort.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.token, tt.sort)
+			if tt.wantMessage == "" {
+				if err != nil {
+					t.Fatalf("decodeCursor() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("decodeCursor() = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != "cursor" {
+				t.Fatalf("decodeCursor() error = %v, want a cursor *ValidationError", err)
+			}
+			if !strings.Contains(validationErr.Message, tt.wantMessage) {
+				t.Errorf("decodeCursor() message = %q, want %q", validationErr.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct{ limit, want int }{
+		{-1, DefaultPageSize},
+		{0, DefaultPageSize},
+		{25, 25},
+		{MaxPageSize, MaxPageSize},
+		{MaxPageSize + 1, MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		if got := pageLimit(tt.limit); got != tt.want {
+			t.Errorf("pageLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// emailVerificationTokenTTL is how long an emailed verification link stays valid
+	emailVerificationTokenTTL = 48 * time.Hour
+
+	// emailVerificationLimit caps resent verification emails per account
+	// within emailVerificationWindow
+	emailVerificationLimit  = 3
+	emailVerificationWindow = time.Hour
+)
+
+// issueEmailVerification creates a token for the user's current email and
+// publishes it for the mailer. Older tokens for the user are invalidated.
+func (s *svc) issueEmailVerification(ctx context.Context, user *User) error {
+	if err := s.repo.InvalidateEmailVerificationTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(emailVerificationTokenTTL)
+	if err := s.repo.CreateEmailVerificationToken(ctx, user.ID, user.Email, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	event := Event{
+		Type:       EventEmailVerificationRequested,
+		UserID:     user.ID,
+		OccurredAt: time.Now(),
+		Data: map[string]string{
+			"email":      user.Email,
+			"token":      token,
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+	}
+	if err := s.events.Publish(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish verification event: %w", err)
+	}
+
+	return nil
+}
+
+// ResendEmailVerification issues a fresh verification token for an unverified
+// user. Callers may resend their own; anyone else's requires users:admin.
+// Returns ErrTooManyVerificationEmails once the account's limit is reached.
+func (s *svc) ResendEmailVerification(ctx context.Context, id uuid.UUID) error {
+	if err := s.authorizeChange(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	recent, err := s.repo.CountEmailVerificationTokensSince(ctx, user.ID, time.Now().Add(-emailVerificationWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count verification tokens: %w", err)
+	}
+	if recent >= emailVerificationLimit {
+		return ErrTooManyVerificationEmails
+	}
+
+	return s.issueEmailVerification(ctx, user)
+}
+
+// ConfirmEmailVerification redeems a verification token. It fails with
+// ErrInvalidToken if the user has changed their email since it was issued.
+func (s *svc) ConfirmEmailVerification(ctx context.Context, req ConfirmEmailVerificationRequest) (*UserResponse, error) {
+	userID, email, err := s.repo.ConsumeEmailVerificationToken(ctx, hashToken(req.Token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem verification token: %w", err)
+	}
+
+	user, err := s.repo.MarkEmailVerified(ctx, userID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
@@ -0,0 +1,126 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors returned by the repository and service layers.
+// Callers should compare with errors.Is since they are usually wrapped.
+var (
+	// ErrUserNotFound is returned when no matching user exists
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrRevisionNotFound is returned when a user has no matching history revision
+	ErrRevisionNotFound = errors.New("user revision not found")
+
+	// ErrConflict is returned when a write violates a uniqueness rule
+	ErrConflict = errors.New("user already exists")
+
+	// ErrValidation is returned when input fails a domain rule
+	ErrValidation = errors.New("validation failed")
+
+	// ErrPreconditionFailed is returned when a conditional write does not apply
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrUnauthenticated is returned when an operation needs a caller and there is none
+	ErrUnauthenticated = errors.New("authentication required")
+
+	// ErrForbidden is returned when the caller may not perform an operation
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrInvalidCredentials is returned when a login or password does not match.
+	// It deliberately does not say which one was wrong.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrAccountInactive is returned when valid credentials belong to a suspended account
+	ErrAccountInactive = errors.New("account is not active")
+
+	// ErrAccountLocked is returned while a user or client IP is locked out
+	// after too many failed logins
+	ErrAccountLocked = errors.New("too many failed login attempts")
+
+	// ErrInvalidToken is returned when an emailed token is unknown, used or expired
+	ErrInvalidToken = errors.New("invalid or expired token")
+
+	// ErrEmailAlreadyVerified is returned when verifying an already verified email
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+
+	// ErrTooManyVerificationEmails is returned when an account has been sent
+	// too many verification emails recently
+	ErrTooManyVerificationEmails = errors.New("too many verification emails")
+
+	// ErrMFAUnavailable is returned when TOTP is used without an encryption key configured
+	ErrMFAUnavailable = errors.New("multi-factor authentication is not configured")
+
+	// ErrMFANotEnrolled is returned when a user has no confirmed TOTP authenticator
+	ErrMFANotEnrolled = errors.New("multi-factor authentication not enrolled")
+
+	// ErrMFAAlreadyEnabled is returned when enrolling a user whose TOTP is already confirmed
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication already enabled")
+
+	// ErrInvalidMFACode is returned when a TOTP or recovery code is wrong, expired or reused
+	ErrInvalidMFACode = errors.New("invalid verification code")
+
+	// ErrInvalidStatusTransition is returned when a user cannot move to the requested status
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
+
+// ConflictError describes which unique field caused a conflict
+type ConflictError struct {
+	Field string // json name of the conflicting field, e.g. "email"
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already in use", e.Field)
+}
+
+// Is lets errors.Is(err, ErrConflict) match a *ConflictError
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ForbiddenError explains why the caller was denied
+type ForbiddenError struct {
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrForbidden, e.Reason)
+}
+
+// Is lets errors.Is(err, ErrForbidden) match a *ForbiddenError
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// ValidationError describes a domain rule violated by a single field
+type ValidationError struct {
+	Field   string
+	Rule    string // short rule name, e.g. "required"
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Is lets errors.Is(err, ErrValidation) match a *ValidationError
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// LockedError reports how long a login lockout has left
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+// Is lets errors.Is(err, ErrAccountLocked) match a *LockedError
+func (e *LockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
@@ -0,0 +1,69 @@
+package users
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Users are served with a strong ETag derived from their row version, e.g. "7".
+// Projections (?fields=) are a different representation of the same version
+// and get a weak ETag, e.g. W/"7", which If-None-Match accepts and If-Match
+// does not.
+
+// formatETag returns the ETag header value for a user version
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// formatWeakETag returns the ETag header value for a projection of a user version
+func formatWeakETag(version int) string {
+	return "W/" + formatETag(version)
+}
+
+// parseIfMatch returns the versions listed in an If-Match header, or nil when
+// the header is absent or "*" (any current version). If-Match uses strong
+// comparison, so weak and malformed tags are dropped; a header made only of
+// those yields an empty, non-nil list that no version matches.
+func parseIfMatch(header string) []int {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := parseETag(strings.TrimSpace(tag)); ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// noneMatch reports whether an If-None-Match header lists the given version.
+// If-None-Match uses weak comparison, so W/ prefixes are ignored.
+func noneMatch(header string, version int) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETag reads a strong ETag produced by formatETag
+func parseETag(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
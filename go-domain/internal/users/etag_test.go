@@ -0,0 +1,68 @@
+package users
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []int
+	}{
+		{name: "absent", header: "", want: nil},
+		{name: "any version", header: "*", want: nil},
+		{name: "single tag", header: `"7"`, want: []int{7}},
+		{name: "list with spaces", header: ` "7" , "8"`, want: []int{7, 8}},
+		{name: "weak tag dropped", header: `W/"7", "8"`, want: []int{8}},
+		{name: "only weak tags", header: `W/"7"`, want: []int{}},
+		{name: "unquoted", header: `7`, want: []int{}},
+		{name: "not a version", header: `"abc"`, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseIfMatch(tt.header)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("parseIfMatch(%q) = %#v, want %#v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "absent", header: "", want: false},
+		{name: "any version", header: "*", want: true},
+		{name: "same version", header: `"7"`, want: true},
+		{name: "other version", header: `"6"`, want: false},
+		{name: "weak tag of the same version", header: `W/"7"`, want: true},
+		{name: "in a list", header: `"5", W/"7"`, want: true},
+		{name: "unquoted", header: `7`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noneMatch(tt.header, 7); got != tt.want {
+				t.Errorf("noneMatch(%q, 7) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatETag(t *testing.T) {
+	if got := formatETag(7); got != `"7"` {
+		t.Errorf("formatETag(7) = %s, want \"7\"", got)
+	}
+	if got := formatWeakETag(7); got != `W/"7"` {
+		t.Errorf("formatWeakETag(7) = %s, want W/\"7\"", got)
+	}
+	if !noneMatch(formatWeakETag(7), 7) || len(parseIfMatch(formatWeakETag(7))) != 0 {
+		t.Error("weak ETags must satisfy If-None-Match but not If-Match")
+	}
+}
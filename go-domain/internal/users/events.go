@@ -0,0 +1,46 @@
+package users
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types emitted by the users service
+const (
+	// EventPasswordResetRequested carries the reset token for the mailer
+	EventPasswordResetRequested = "user.password_reset_requested"
+
+	// EventEmailVerificationRequested carries the verification token for the mailer
+	EventEmailVerificationRequested = "user.email_verification_requested"
+)
+
+// Event is a domain event for consumers outside the users package (e.g. a mailer)
+type Event struct {
+	Type       string            `json:"type"`
+	UserID     uuid.UUID         `json:"user_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+// EventPublisher delivers domain events
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// logPublisher writes events to the log. Event data is omitted because it
+// may contain secrets such as reset tokens.
+type logPublisher struct{}
+
+// NewLogPublisher returns a publisher that only logs events.
+// It is the default until a mailer or message queue is wired in.
+func NewLogPublisher() EventPublisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(ctx context.Context, event Event) error {
+	slog.InfoContext(ctx, "Domain event", "type", event.Type, "user_id", event.UserID, "occurred_at", event.OccurredAt)
+	return nil
+}
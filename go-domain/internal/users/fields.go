@@ -0,0 +1,135 @@
+package users
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldMask names json fields of a request or response, e.g. the
+// ?fields=id,username,email of a user listing. An empty mask means every field.
+type FieldMask []string
+
+// The fields a mask may name, read from the json tags so they always match
+// what is serialized
+var (
+	// UserResponseFields can be selected with ?fields= on reads
+	UserResponseFields = jsonFieldNames(reflect.TypeOf(UserResponse{}))
+
+	// UpdateUserFields can be written with ?update_mask= on PATCH
+	UpdateUserFields = jsonFieldNames(reflect.TypeOf(UpdateUserRequest{}))
+)
+
+// ParseFieldMask parses a comma separated list of field names, skipping
+// blanks and repeats. Names missing from schema are returned as unknown.
+func ParseFieldMask(raw string, schema []string) (mask FieldMask, unknown []string) {
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "" || slices.Contains(mask, name):
+		case !slices.Contains(schema, name):
+			unknown = append(unknown, name)
+		default:
+			mask = append(mask, name)
+		}
+	}
+	return mask, unknown
+}
+
+// Has reports whether the mask includes field; an empty mask includes all
+func (m FieldMask) Has(field string) bool {
+	return len(m) == 0 || slices.Contains(m, field)
+}
+
+// Project returns the fields of resp named by the mask, keyed by json name.
+// Empty optional fields are left out as in the full response.
+func (m FieldMask) Project(resp *UserResponse) map[string]any {
+	projected := make(map[string]any, len(m))
+	value := reflect.ValueOf(resp).Elem()
+	for i := range value.NumField() {
+		name, omitEmpty := jsonField(value.Type().Field(i))
+		if name == "" || !m.Has(name) {
+			continue
+		}
+		if field := value.Field(i); !omitEmpty || !field.IsZero() {
+			projected[name] = field.Interface()
+		}
+	}
+	return projected
+}
+
+// Masked returns the update described by req restricted to the fields of
+// mask. Fields outside the mask are ignored even when req sets them; masked
+// names missing from req are cleared, which only optional fields allow.
+// An empty mask keeps req as is, updating the fields it sets.
+func (req UpdateUserRequest) Masked(mask FieldMask) (UpdateUserRequest, error) {
+	if len(mask) == 0 {
+		return req, nil
+	}
+
+	// An empty name is stored as NULL
+	empty := ""
+	var masked UpdateUserRequest
+	var missing string
+	for _, field := range mask {
+		switch field {
+		case "username":
+			masked.Username = req.Username
+			if req.Username == nil {
+				missing = field
+			}
+		case "email":
+			masked.Email = req.Email
+			if req.Email == nil {
+				missing = field
+			}
+		case "first_name":
+			masked.FirstName = &empty
+			if req.FirstName != nil {
+				masked.FirstName = req.FirstName
+			}
+		case "last_name":
+			masked.LastName = &empty
+			if req.LastName != nil {
+				masked.LastName = req.LastName
+			}
+		case "is_active":
+			masked.IsActive = req.IsActive
+			if req.IsActive == nil {
+				missing = field
+			}
+		}
+		if missing != "" {
+			return req, &ValidationError{Field: missing, Rule: "required", Message: "is in update_mask and cannot be cleared"}
+		}
+	}
+	return masked, nil
+}
+
+// jsonFieldNames lists the serialized field names of a struct type
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		if name, _ := jsonField(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonField returns the json name of a struct field and whether it has
+// omitempty. The name is empty for fields that are never serialized.
+func jsonField(field reflect.StructField) (name string, omitEmpty bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, slices.Contains(strings.Split(options, ","), "omitempty")
+}
@@ -0,0 +1,87 @@
+package users
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMasked(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	active := true
+	full := UpdateUserRequest{
+		Username:  ptr("alice"),
+		Email:     ptr("alice@example.com"),
+		FirstName: ptr("Alice"),
+		LastName:  ptr("Liddell"),
+		IsActive:  &active,
+	}
+
+	tests := []struct {
+		name        string
+		req         UpdateUserRequest
+		mask        FieldMask
+		want        UpdateUserRequest
+		wantMissing string // field reported as required, empty when valid
+	}{
+		{name: "empty mask keeps the request", req: full, mask: nil, want: full},
+		{name: "fields outside the mask are ignored", req: full, mask: FieldMask{"email"}, want: UpdateUserRequest{Email: full.Email}},
+		{
+			name: "several fields",
+			req:  full,
+			mask: FieldMask{"username", "is_active"},
+			want: UpdateUserRequest{Username: full.Username, IsActive: &active},
+		},
+		{
+			name: "missing names are cleared",
+			req:  UpdateUserRequest{Username: ptr("alice")},
+			mask: FieldMask{"first_name", "last_name"},
+			want: UpdateUserRequest{FirstName: ptr(""), LastName: ptr("")},
+		},
+		{name: "missing username", req: UpdateUserRequest{Email: ptr("a@example.com")}, mask: FieldMask{"email", "username"}, wantMissing: "username"},
+		{name: "missing email", req: UpdateUserRequest{}, mask: FieldMask{"email"}, wantMissing: "email"},
+		{name: "missing is_active", req: UpdateUserRequest{}, mask: FieldMask{"is_active"}, wantMissing: "is_active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Masked(tt.mask)
+			if tt.wantMissing != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.wantMissing || validationErr.Rule != "required" {
+					t.Fatalf("Masked() error = %v, want required %s", err, tt.wantMissing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Masked() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Masked() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldMask(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		wantMask    FieldMask
+		wantUnknown []string
+	}{
+		{name: "empty", raw: ""},
+		{name: "blanks and repeats", raw: " email,, username ,email", wantMask: FieldMask{"email", "username"}},
+		{name: "unknown names", raw: "id,password_hash,secret", wantMask: FieldMask{"id"}, wantUnknown: []string{"password_hash", "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, unknown := ParseFieldMask(tt.raw, UserResponseFields)
+			if !slices.Equal(mask, tt.wantMask) || !slices.Equal(unknown, tt.wantUnknown) {
+				t.Errorf("ParseFieldMask(%q) = %v, %v, want %v, %v", tt.raw, mask, unknown, tt.wantMask, tt.wantUnknown)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package users
 
 import (
+	"errors"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -24,142 +29,757 @@ func (h *handler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		slog.Error("Failed to bind request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.BindError(c, err)
 		return
 	}
 
 	user, err := h.service.CreateUser(c.Request.Context(), req)
 	if err != nil {
 		slog.Error("Failed to create user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create user",
-		})
+		respondError(c, err, "Failed to create user")
 		return
 	}
 
 	c.JSON(http.StatusCreated, user)
 }
 
-// GetUser handles GET /users/:id
+// GetUser handles GET /users/:id (?fields=... for a sparse fieldset)
 func (h *handler) GetUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
-		})
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	// ?fields=id,username,email returns only those fields
+	var fieldErrors []problem.FieldError
+	fields := parseFieldMask(c, "fields", UserResponseFields, func(field, rule, message string) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: field, Rule: rule, Message: message})
+	})
+	if len(fields) > 0 && c.Query("as_of") != "" {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: "fields", Rule: "excluded_with", Message: "cannot be combined with as_of"})
+	}
+	if len(fieldErrors) > 0 {
+		problem.Validation(c, "Invalid fields", fieldErrors)
+		return
+	}
+
+	// ?as_of=<RFC 3339 timestamp> reads the version current at that time
+	if raw := c.Query("as_of"); raw != "" {
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			problem.Validation(c, "Invalid as_of timestamp", []problem.FieldError{{
+				Field:   "as_of",
+				Rule:    "datetime",
+				Message: "must be an RFC 3339 timestamp",
+			}})
+			return
+		}
+
+		version, err := h.service.GetUserAsOf(c.Request.Context(), id, at)
+		if err != nil {
+			slog.Error("Failed to get user version", "error", err, "id", id, "as_of", at)
+			respondError(c, err, "Failed to get user")
+			return
+		}
+
+		c.JSON(http.StatusOK, version)
 		return
 	}
 
-	user, err := h.service.GetUserByID(c.Request.Context(), id)
+	user, err := h.service.GetUserByID(c.Request.Context(), id, fields)
 	if err != nil {
 		slog.Error("Failed to get user", "error", err, "id", id)
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
-		})
+		respondError(c, err, "Failed to get user")
 		return
 	}
 
+	if len(fields) > 0 {
+		c.Header("ETag", formatWeakETag(user.Version))
+	} else {
+		c.Header("ETag", formatETag(user.Version))
+	}
+	if noneMatch(c.GetHeader("If-None-Match"), user.Version) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if len(fields) > 0 {
+		c.JSON(http.StatusOK, fields.Project(user))
+		return
+	}
 	c.JSON(http.StatusOK, user)
 }
 
-// ListUsers handles GET /users
+// ListUsers handles GET /users?status=...&verified=...&created_after=...&created_before=...
+// &updated_after=...&updated_before=...&name=...&email=...&sort=-created_at&limit=...&fields=...
+// Pages are selected with ?cursor= (from a previous response's next_cursor or
+// prev_cursor) or with ?offset=.
 func (h *handler) ListUsers(c *gin.Context) {
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	query, fieldErrors := parseUserQuery(c)
+	if len(fieldErrors) > 0 {
+		problem.Validation(c, "Invalid user query", fieldErrors)
+		return
+	}
 
 	// Get users from service
-	users, err := h.service.ListUsers(c.Request.Context(), limit, offset)
+	result, err := h.service.ListUsers(c.Request.Context(), query)
 	if err != nil {
 		slog.Error("Failed to list users", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch users",
-		})
+		respondError(c, err, "Failed to fetch users")
+		return
+	}
+
+	// Success response with pagination info
+	pagination := gin.H{
+		"limit": query.Page.Limit,
+		"total": result.Total,
+		"sort":  query.Sort.String(),
+	}
+	if query.Page.Cursor == "" {
+		pagination["offset"] = query.Page.Offset
+	}
+	if result.NextCursor != "" {
+		pagination["next_cursor"] = result.NextCursor
+	}
+	if result.PrevCursor != "" {
+		pagination["prev_cursor"] = result.PrevCursor
+	}
+
+	var data any = result.Users
+	if len(query.Fields) > 0 {
+		projected := make([]map[string]any, len(result.Users))
+		for i, user := range result.Users {
+			projected[i] = query.Fields.Project(user)
+		}
+		data = projected
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       data,
+		"pagination": pagination,
+	})
+}
+
+// parseUserQuery reads a user listing query from the query string,
+// collecting an error for every invalid parameter
+func parseUserQuery(c *gin.Context) (UserQuery, []problem.FieldError) {
+	var fieldErrors []problem.FieldError
+	invalid := func(field, rule, message string) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	// Parse pagination parameters
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultPageSize)))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	query := UserQuery{
+		Sort: DefaultSort,
+		Page: PageRequest{Limit: pageLimit(limit), Offset: max(offset, 0), Cursor: c.Query("cursor")},
+	}
+	if query.Page.Cursor != "" && c.Query("offset") != "" {
+		invalid("offset", "excluded_with", "cannot be combined with cursor")
+	}
+
+	// Parse sort, e.g. ?sort=-created_at for newest first
+	if raw := c.Query("sort"); raw != "" {
+		sort, ok := ParseSort(raw)
+		if !ok {
+			invalid("sort", "oneof", "must be one of "+strings.Join(SortableFields, ", ")+", prefixed with - for descending order")
+		}
+		query.Sort = sort
+	}
+
+	query.Filter = parseStatusFilter(c, invalid)
+
+	// Parse sparse fieldset, e.g. ?fields=id,username,email
+	query.Fields = parseFieldMask(c, "fields", UserResponseFields, invalid)
+
+	// Parse date ranges; after is inclusive, before exclusive
+	parseTime := func(param string) *time.Time {
+		raw := c.Query(param)
+		if raw == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			invalid(param, "datetime", "must be an RFC 3339 timestamp")
+			return nil
+		}
+		return &t
+	}
+	query.Filter.CreatedAfter = parseTime("created_after")
+	query.Filter.CreatedBefore = parseTime("created_before")
+	query.Filter.UpdatedAfter = parseTime("updated_after")
+	query.Filter.UpdatedBefore = parseTime("updated_before")
+	if after, before := query.Filter.CreatedAfter, query.Filter.CreatedBefore; after != nil && before != nil && !after.Before(*before) {
+		invalid("created_before", "gtfield", "must be later than created_after")
+	}
+	if after, before := query.Filter.UpdatedAfter, query.Filter.UpdatedBefore; after != nil && before != nil && !after.Before(*before) {
+		invalid("updated_before", "gtfield", "must be later than updated_after")
+	}
+
+	// Parse prefix searches
+	query.Filter.NamePrefix = strings.TrimSpace(c.Query("name"))
+	query.Filter.EmailPrefix = strings.TrimSpace(c.Query("email"))
+	if len(query.Filter.NamePrefix) > 255 {
+		invalid("name", "max", "must be at most 255 characters")
+	}
+	if len(query.Filter.EmailPrefix) > 255 {
+		invalid("email", "max", "must be at most 255 characters")
+	}
+
+	return query, fieldErrors
+}
+
+// parseStatusFilter reads the status and verified filters shared by listing
+// and search, reporting invalid parameters through invalid
+func parseStatusFilter(c *gin.Context, invalid func(field, rule, message string)) ListFilter {
+	var filter ListFilter
+
+	// Parse status filter, e.g. ?status=active,suspended
+	if raw := c.Query("status"); raw != "" {
+		for _, value := range strings.Split(raw, ",") {
+			status := UserStatus(strings.TrimSpace(value))
+			if !status.Valid() {
+				invalid("status", "oneof", "must be a comma separated list of active, suspended or deleted")
+				break
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	// Parse verified filter, e.g. ?verified=false for unverified emails
+	if raw := c.Query("verified"); raw != "" {
+		verified, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid("verified", "boolean", "must be true or false")
+		}
+		filter.Verified = &verified
+	}
+
+	return filter
+}
+
+// parseFieldMask reads a comma separated field mask from a query parameter,
+// reporting names outside schema through invalid
+func parseFieldMask(c *gin.Context, param string, schema []string, invalid func(field, rule, message string)) FieldMask {
+	mask, unknown := ParseFieldMask(c.Query(param), schema)
+	if len(unknown) > 0 {
+		invalid(param, "oneof", "unknown field "+strings.Join(unknown, ", ")+"; must be among "+strings.Join(schema, ", "))
+	}
+	return mask
+}
+
+// SearchUsers handles GET /users/search?q=...&status=...&verified=...&limit=...&offset=...
+// Results are ranked; each carries how it matched and its highlighted fields.
+func (h *handler) SearchUsers(c *gin.Context) {
+	var fieldErrors []problem.FieldError
+	invalid := func(field, rule, message string) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	// Parse pagination parameters
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultPageSize)))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	query := SearchQuery{
+		Text:   strings.TrimSpace(c.Query("q")),
+		Filter: parseStatusFilter(c, invalid),
+		Limit:  pageLimit(limit),
+		Offset: max(offset, 0),
+	}
+	switch {
+	case query.Text == "":
+		invalid("q", "required", "is required")
+	case len(query.Text) > 255:
+		invalid("q", "max", "must be at most 255 characters")
+	case len(searchTerms(query.Text)) == 0:
+		invalid("q", "required", "must contain a letter or digit")
+	}
+	if len(fieldErrors) > 0 {
+		problem.Validation(c, "Invalid search query", fieldErrors)
 		return
 	}
 
-	// Get total count
-	total, err := h.service.GetUsersCount(c.Request.Context())
+	results, err := h.service.SearchUsers(c.Request.Context(), query)
 	if err != nil {
-		slog.Error("Failed to count users", "error", err)
-		// Continue even if count fails
-		total = 0
+		slog.Error("Failed to search users", "error", err)
+		respondError(c, err, "Failed to search users")
+		return
 	}
 
-	// Success response with pagination info
 	c.JSON(http.StatusOK, gin.H{
-		"data": users,
+		"data": results,
 		"pagination": gin.H{
-			"limit":  limit,
-			"offset": offset,
-			"total":  total,
+			"limit":  query.Limit,
+			"offset": query.Offset,
 		},
 	})
 }
 
-// UpdateUser handles PATCH /users/:id
+// UpdateUser handles PATCH /users/:id (?update_mask=... to write exactly those fields)
 func (h *handler) UpdateUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
-		})
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	// ?update_mask=first_name,last_name writes exactly those fields: others in
+	// the body are ignored and masked names missing from it are cleared
+	var fieldErrors []problem.FieldError
+	mask := parseFieldMask(c, "update_mask", UpdateUserFields, func(field, rule, message string) {
+		fieldErrors = append(fieldErrors, problem.FieldError{Field: field, Rule: rule, Message: message})
+	})
+	if len(fieldErrors) > 0 {
+		problem.Validation(c, "Invalid update mask", fieldErrors)
 		return
 	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		slog.Error("Failed to bind request", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.BindError(c, err)
+		return
+	}
+
+	req, err := req.Masked(mask)
+	if err != nil {
+		respondError(c, err, "Failed to update user")
 		return
 	}
 
-	user, err := h.service.UpdateUser(c.Request.Context(), id, req)
+	// If-Match makes the update conditional on the version the client last saw
+	user, err := h.service.UpdateUser(c.Request.Context(), id, req, parseIfMatch(c.GetHeader("If-Match")))
 	if err != nil {
 		slog.Error("Failed to update user", "error", err, "id", id)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update user",
-		})
+		respondError(c, err, "Failed to update user")
 		return
 	}
 
+	c.Header("ETag", formatETag(user.Version))
 	c.JSON(http.StatusOK, user)
 }
 
 // DeleteUser handles DELETE /users/:id
 func (h *handler) DeleteUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid user ID",
-		})
+	id, ok := parseUserID(c)
+	if !ok {
 		return
 	}
 
-	err = h.service.DeleteUser(c.Request.Context(), id)
-	if err != nil {
+	if err := h.service.DeleteUser(c.Request.Context(), id); err != nil {
 		slog.Error("Failed to delete user", "error", err, "id", id)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete user",
-		})
+		respondError(c, err, "Failed to delete user")
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// Authenticate handles POST /users/authenticate
+func (h *handler) Authenticate(c *gin.Context) {
+	var req AuthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	result, err := h.service.Authenticate(c.Request.Context(), req, c.GetString("clientIP"))
+	if err != nil {
+		// Never log the submitted password or which part of the credentials failed
+		slog.Warn("Authentication failed", "error", err)
+		respondError(c, err, "Failed to authenticate")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ChangePassword handles PUT /users/:id/password
+func (h *handler) ChangePassword(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user, err := h.service.ChangePassword(c.Request.Context(), id, req, c.GetString("clientIP"))
+	if err != nil {
+		slog.Error("Failed to change password", "error", err, "id", id)
+		respondError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetPassword handles POST /users/:id/password/reset
+func (h *handler) ResetPassword(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user, err := h.service.ResetPassword(c.Request.Context(), id, req)
+	if err != nil {
+		slog.Error("Failed to reset password", "error", err, "id", id)
+		respondError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// RequestPasswordReset handles POST /users/password-reset.
+// Always answers 202 so callers cannot probe which emails are registered.
+func (h *handler) RequestPasswordReset(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req); err != nil {
+		slog.Error("Failed to request password reset", "error", err)
+		respondError(c, err, "Failed to request password reset")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset handles POST /users/password-reset/confirm
+func (h *handler) ConfirmPasswordReset(c *gin.Context) {
+	var req ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	if err := h.service.ConfirmPasswordReset(c.Request.Context(), req); err != nil {
+		slog.Warn("Failed to confirm password reset", "error", err)
+		respondError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResendEmailVerification handles POST /users/:id/verify-email
+func (h *handler) ResendEmailVerification(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ResendEmailVerification(c.Request.Context(), id); err != nil {
+		slog.Error("Failed to resend email verification", "error", err, "id", id)
+		respondError(c, err, "Failed to send verification email")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ConfirmEmailVerification handles POST /users/verify-email/confirm
+func (h *handler) ConfirmEmailVerification(c *gin.Context) {
+	var req ConfirmEmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user, err := h.service.ConfirmEmailVerification(c.Request.Context(), req)
+	if err != nil {
+		slog.Warn("Failed to confirm email verification", "error", err)
+		respondError(c, err, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SuspendUser handles POST /users/:id/suspend
+func (h *handler) SuspendUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.SuspendUser(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to suspend user", "error", err, "id", id)
+		respondError(c, err, "Failed to suspend user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// RestoreUser handles POST /users/:id/restore
+func (h *handler) RestoreUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.RestoreUser(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to restore user", "error", err, "id", id)
+		respondError(c, err, "Failed to restore user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// EnrollTOTP handles POST /users/:id/mfa/totp
+func (h *handler) EnrollTOTP(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.service.EnrollTOTP(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to enroll TOTP", "error", err, "id", id)
+		respondError(c, err, "Failed to enroll authenticator")
+		return
+	}
+
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// ConfirmTOTP handles POST /users/:id/mfa/totp/confirm
+func (h *handler) ConfirmTOTP(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(c.Request.Context(), id, req)
+	if err != nil {
+		slog.Error("Failed to confirm TOTP", "error", err, "id", id)
+		respondError(c, err, "Failed to confirm authenticator")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// VerifyMFA handles POST /users/mfa/verify
+func (h *handler) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	result, err := h.service.VerifyMFA(c.Request.Context(), req, c.GetString("clientIP"))
+	if err != nil {
+		// Never log the submitted challenge or code
+		slog.Warn("MFA verification failed", "error", err)
+		respondError(c, err, "Failed to verify code")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegenerateRecoveryCodes handles POST /users/:id/mfa/recovery-codes
+func (h *handler) RegenerateRecoveryCodes(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to regenerate recovery codes", "error", err, "id", id)
+		respondError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// UnlockUser handles POST /users/:id/unlock
+func (h *handler) UnlockUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnlockUser(c.Request.Context(), id); err != nil {
+		slog.Error("Failed to unlock user", "error", err, "id", id)
+		respondError(c, err, "Failed to unlock user")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserHistory handles GET /users/:id/history
+func (h *handler) ListUserHistory(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	// Parse pagination parameters
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	limit = pageLimit(limit)
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	offset = max(offset, 0)
+
+	versions, err := h.service.ListUserHistory(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		slog.Error("Failed to list user history", "error", err, "id", id)
+		respondError(c, err, "Failed to fetch user history")
+		return
+	}
+
+	total, err := h.service.CountUserHistory(c.Request.Context(), id)
+	if err != nil {
+		slog.Error("Failed to count user history", "error", err, "id", id)
+		// Continue even if count fails
+		total = 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": versions,
+		"pagination": gin.H{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// RevertUser handles POST /users/:id/history/:revision/revert
+func (h *handler) RevertUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		problem.BadRequest(c, "Invalid revision")
+		return
+	}
+
+	user, err := h.service.RevertUser(c.Request.Context(), id, revision)
+	if err != nil {
+		slog.Error("Failed to revert user", "error", err, "id", id, "revision", revision)
+		respondError(c, err, "Failed to revert user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// parseUserID reads the :id path parameter, writing a 400 problem if it is invalid
+func parseUserID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		problem.BadRequest(c, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondError maps domain errors to problem responses.
+// Unknown errors are reported as 500 with the given fallback detail.
+func respondError(c *gin.Context, err error, fallback string) {
+	var conflictErr *ConflictError
+	var validationErr *ValidationError
+	var policyErr *PasswordPolicyError
+	var lockedErr *LockedError
+	var forbiddenErr *ForbiddenError
+
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		problem.Abort(c, http.StatusNotFound, problem.TypeNotFound, "User not found")
+	case errors.Is(err, ErrRevisionNotFound):
+		problem.Abort(c, http.StatusNotFound, problem.TypeNotFound, "User revision not found")
+	case errors.Is(err, ErrUnauthenticated):
+		problem.Abort(c, http.StatusUnauthorized, problem.TypeUnauthorized, "Authentication required")
+	case errors.As(err, &forbiddenErr):
+		problem.Abort(c, http.StatusForbidden, problem.TypeForbidden, forbiddenErr.Reason)
+	case errors.As(err, &conflictErr):
+		p := problem.New(http.StatusConflict, problem.TypeConflict, conflictErr.Error())
+		p.Errors = []problem.FieldError{{
+			Field:   conflictErr.Field,
+			Rule:    "unique",
+			Message: "is already in use",
+		}}
+		problem.Write(c, p)
+	case errors.As(err, &validationErr):
+		problem.Validation(c, validationErr.Error(), []problem.FieldError{{
+			Field:   validationErr.Field,
+			Rule:    validationErr.Rule,
+			Message: validationErr.Message,
+		}})
+	case errors.Is(err, ErrInvalidCredentials):
+		problem.Abort(c, http.StatusUnauthorized, problem.TypeUnauthorized, "Invalid login or password")
+	case errors.As(err, &lockedErr):
+		// Round up so clients never retry a moment too early
+		seconds := int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		problem.Abort(c, http.StatusTooManyRequests, problem.TypeTooManyRequests, "Too many failed login attempts, try again later")
+	case errors.Is(err, ErrAccountInactive):
+		problem.Abort(c, http.StatusForbidden, problem.TypeForbidden, "Account is not active")
+	case errors.Is(err, ErrInvalidToken):
+		problem.BadRequest(c, "Token is invalid or has expired")
+	case errors.Is(err, ErrEmailAlreadyVerified):
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "Email is already verified")
+	case errors.Is(err, ErrTooManyVerificationEmails):
+		problem.Abort(c, http.StatusTooManyRequests, problem.TypeTooManyRequests, "Too many verification emails, try again later")
+	case errors.Is(err, ErrInvalidMFACode):
+		problem.Abort(c, http.StatusUnauthorized, problem.TypeUnauthorized, "Invalid verification code")
+	case errors.Is(err, ErrMFANotEnrolled):
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "Multi-factor authentication is not enabled")
+	case errors.Is(err, ErrMFAAlreadyEnabled):
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "Multi-factor authentication is already enabled")
+	case errors.Is(err, ErrMFAUnavailable):
+		problem.Abort(c, http.StatusServiceUnavailable, problem.TypeDefault, "Multi-factor authentication is not available")
+	case errors.Is(err, ErrInvalidStatusTransition):
+		problem.Abort(c, http.StatusConflict, problem.TypeConflict, "User cannot move to the requested status")
+	case errors.As(err, &policyErr):
+		fields := make([]problem.FieldError, len(policyErr.Violations))
+		for i, v := range policyErr.Violations {
+			fields[i] = problem.FieldError{Field: policyErr.Field, Rule: v.Rule, Message: v.Message}
+		}
+		problem.Validation(c, "Password does not meet the password policy", fields)
+	case errors.Is(err, ErrPreconditionFailed):
+		problem.Abort(c, http.StatusPreconditionFailed, problem.TypePreconditionFailed, "User was modified since it was read")
+	default:
+		problem.Internal(c, fallback)
+	}
+}
+
 // Common HTTP Status Codes:
 // Success
 //http.StatusOK                    // 200 - Success
 //http.StatusCreated               // 201 - Resource created
 //http.StatusNoContent             // 204 - Success but no content
+//http.StatusNotModified           // 304 - Cached representation is current
 
 // Client Errors
 //http.StatusBadRequest            // 400 - Invalid request
@@ -167,6 +787,9 @@ func (h *handler) DeleteUser(c *gin.Context) {
 //http.StatusForbidden             // 403 - Not authorized
 //http.StatusNotFound              // 404 - Resource not found
 //http.StatusConflict              // 409 - Resource conflict
+//http.StatusPreconditionFailed    // 412 - Conditional request failed
+//http.StatusUnprocessableEntity   // 422 - Semantically invalid input
+//http.StatusTooManyRequests       // 429 - Rate limited or locked out
 
 // Server Errors
 //http.StatusInternalServerError   // 500 - Server error
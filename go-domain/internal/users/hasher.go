@@ -0,0 +1,148 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errUnknownHashFormat is returned for stored hashes no hasher understands
+var errUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher hashes passwords into PHC-format strings and verifies them.
+// Verify also accepts hashes from older algorithms and reports when a hash
+// should be replaced with one using the current algorithm and settings.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (match bool, needsRehash bool, err error)
+}
+
+// Argon2Params configures argon2id hashing
+type Argon2Params struct {
+	Memory      uint32 // memory in KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params returns the OWASP recommended minimum settings
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      19 * 1024, // 19 MiB
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Validate reports settings argon2id cannot hash with. argon2.IDKey panics
+// on a parallelism of zero, so this runs at startup rather than on first use.
+func (p Argon2Params) Validate() error {
+	switch {
+	case p.Memory == 0:
+		return errors.New("argon2 memory must be positive")
+	case p.Iterations == 0:
+		return errors.New("argon2 iterations must be positive")
+	case p.Parallelism == 0:
+		return errors.New("argon2 parallelism must be between 1 and 255")
+	case p.SaltLength == 0 || p.KeyLength == 0:
+		return errors.New("argon2 salt and key lengths must be positive")
+	}
+	return nil
+}
+
+// argon2Hasher hashes with argon2id and still verifies legacy bcrypt hashes
+type argon2Hasher struct {
+	params Argon2Params
+}
+
+// NewPasswordHasher creates the default hasher using argon2id
+func NewPasswordHasher(params Argon2Params) PasswordHasher {
+	return &argon2Hasher{
+		params: params,
+	}
+}
+
+// Hash returns $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (h *argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against an argon2id or bcrypt hash.
+// bcrypt hashes and argon2id hashes with outdated settings need a rehash.
+func (h *argon2Hasher) Verify(password, encoded string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return h.verifyArgon2(password, encoded)
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("failed to verify bcrypt hash: %w", err)
+		}
+		return true, true, nil
+	default:
+		return false, false, errUnknownHashFormat
+	}
+}
+
+// verifyArgon2 recomputes the key with the parameters stored in the hash
+func (h *argon2Hasher) verifyArgon2(password, encoded string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, false, errUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return false, false, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, fmt.Errorf("invalid argon2 key: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return false, false, nil
+	}
+
+	return true, params != h.params, nil
+}
@@ -0,0 +1,55 @@
+package users
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hasher := NewPasswordHasher(testArgon2Params)
+	current, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	stronger := testArgon2Params
+	stronger.Iterations++
+	outdated, err := NewPasswordHasher(stronger).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		encoded    string
+		wantMatch  bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{name: "argon2id", password: "correct horse", encoded: current, wantMatch: true},
+		{name: "argon2id wrong password", password: "wrong", encoded: current},
+		{name: "argon2id other settings", password: "correct horse", encoded: outdated, wantMatch: true, wantRehash: true},
+		{name: "bcrypt", password: "correct horse", encoded: string(legacy), wantMatch: true, wantRehash: true},
+		{name: "bcrypt wrong password", password: "wrong", encoded: string(legacy)},
+		{name: "truncated argon2id", password: "correct horse", encoded: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", wantErr: errUnknownHashFormat},
+		{name: "unknown format", password: "correct horse", encoded: "plaintext", wantErr: errUnknownHashFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, rehash, err := hasher.Verify(tt.password, tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if match != tt.wantMatch || rehash != tt.wantRehash {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, rehash, tt.wantMatch, tt.wantRehash)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListUserHistory retrieves a user's versions, newest first. Deleted users
+// keep their history. Returns ErrUserNotFound for users that never existed.
+func (s *svc) ListUserHistory(ctx context.Context, id uuid.UUID, limit, offset int) ([]*UserVersionResponse, error) {
+	if err := s.authorizeRead(ctx, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.ListHistory(ctx, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user history: %w", err)
+	}
+	if len(versions) == 0 && offset <= 0 {
+		// Every user has at least the version written when it was created
+		return nil, ErrUserNotFound
+	}
+
+	responses := make([]*UserVersionResponse, len(versions))
+	for i, version := range versions {
+		response := version.ToResponse()
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+// CountUserHistory returns the number of versions recorded for a user
+func (s *svc) CountUserHistory(ctx context.Context, id uuid.UUID) (int64, error) {
+	if err := s.authorizeRead(ctx, id); err != nil {
+		return 0, err
+	}
+
+	count, err := s.repo.CountHistory(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count user history: %w", err)
+	}
+	return count, nil
+}
+
+// GetUserAsOf returns the version of a user that was current at time at.
+// Returns ErrRevisionNotFound if the user did not exist yet at that time.
+func (s *svc) GetUserAsOf(ctx context.Context, id uuid.UUID, at time.Time) (*UserVersionResponse, error) {
+	if err := s.authorizeRead(ctx, id); err != nil {
+		return nil, err
+	}
+
+	version, err := s.repo.GetVersionAt(ctx, id, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user version: %w", err)
+	}
+
+	response := version.ToResponse()
+	return &response, nil
+}
+
+// RevertUser restores a user's profile (username, email and names) to an
+// earlier revision. The revert is an ordinary update: it is audited, records a
+// new version and, if the email changes, requires it to be verified again.
+// Status is left alone; lifecycle changes have their own operations.
+func (s *svc) RevertUser(ctx context.Context, id uuid.UUID, revision int) (*UserResponse, error) {
+	if err := s.authorizeChange(ctx, id); err != nil {
+		return nil, err
+	}
+
+	target, err := s.repo.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user version: %w", err)
+	}
+
+	// Empty strings clear the names, matching how UpdateUser treats them
+	firstName, lastName := "", ""
+	if target.FirstName != nil {
+		firstName = *target.FirstName
+	}
+	if target.LastName != nil {
+		lastName = *target.LastName
+	}
+
+	user, err := s.UpdateUser(ctx, id, UpdateUserRequest{
+		Username:  &target.Username,
+		Email:     &target.Email,
+		FirstName: &firstName,
+		LastName:  &lastName,
+	}, nil)
+	if err != nil {
+		// A *ConflictError means someone else has taken the old username or email
+		return nil, fmt.Errorf("failed to revert user: %w", err)
+	}
+
+	return user, nil
+}
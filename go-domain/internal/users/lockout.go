@@ -0,0 +1,147 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Login throttle subjects
+const (
+	throttleUser = "user" // keyed by user id
+	throttleIP   = "ip"   // keyed by client IP from middleware.RealIP
+)
+
+// Lockout event actions
+const (
+	lockoutActionLocked   = "locked"
+	lockoutActionUnlocked = "unlocked"
+)
+
+// LockoutConfig configures brute-force protection for Authenticate
+type LockoutConfig struct {
+	UserThreshold int           // failed attempts per account before a lockout
+	IPThreshold   int           // failed attempts per client IP before a lockout
+	Window        time.Duration // failures older than this no longer count
+	BaseDuration  time.Duration // first lockout length, doubled on every repeat
+	MaxDuration   time.Duration // upper bound for a single lockout
+}
+
+// DefaultLockoutConfig returns lockout settings with sensible defaults
+func DefaultLockoutConfig() LockoutConfig {
+	return LockoutConfig{
+		UserThreshold: 5,
+		IPThreshold:   50,
+		Window:        15 * time.Minute,
+		BaseDuration:  time.Minute,
+		MaxDuration:   time.Hour,
+	}
+}
+
+// LoginThrottle is the failed-login state of one user or client IP
+type LoginThrottle struct {
+	SubjectType    string
+	Subject        string
+	FailedAttempts int
+	Lockouts       int // lockouts since the last successful login or unlock
+	LastFailedAt   *time.Time
+	LockedUntil    *time.Time
+}
+
+// LockoutEvent is an audit entry for a lockout or an admin unlock
+type LockoutEvent struct {
+	Action         string
+	SubjectType    string
+	Subject        string
+	UserID         *uuid.UUID
+	ClientIP       string
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// checkLocked returns a *LockedError while the subject is locked out
+func (s *svc) checkLocked(ctx context.Context, subjectType, subject string) error {
+	throttle, err := s.repo.GetLoginThrottle(ctx, subjectType, subject)
+	if err != nil {
+		return fmt.Errorf("failed to get login throttle: %w", err)
+	}
+
+	if throttle.LockedUntil != nil {
+		if remaining := time.Until(*throttle.LockedUntil); remaining > 0 {
+			return &LockedError{RetryAfter: remaining}
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and locks the subject once the
+// threshold is reached. Each repeated lockout doubles in length, up to
+// MaxDuration. Errors are logged so they never change the login response.
+func (s *svc) recordLoginFailure(ctx context.Context, subjectType, subject string, threshold int, userID *uuid.UUID, clientIP string) {
+	throttle, err := s.repo.RecordLoginFailure(ctx, subjectType, subject, s.lockout.Window)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record login failure", "error", err, "subject_type", subjectType)
+		return
+	}
+	if throttle.FailedAttempts < threshold {
+		return
+	}
+
+	duration := s.lockout.BaseDuration << min(throttle.Lockouts, 16)
+	if duration <= 0 || duration > s.lockout.MaxDuration {
+		duration = s.lockout.MaxDuration
+	}
+	lockedUntil := time.Now().Add(duration)
+
+	event := LockoutEvent{
+		Action:         lockoutActionLocked,
+		SubjectType:    subjectType,
+		Subject:        subject,
+		UserID:         userID,
+		ClientIP:       clientIP,
+		FailedAttempts: throttle.FailedAttempts,
+		LockedUntil:    &lockedUntil,
+	}
+	if err := s.repo.LockLoginSubject(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "Failed to lock login subject", "error", err, "subject_type", subjectType)
+		return
+	}
+
+	slog.WarnContext(ctx, "Login locked out",
+		"subject_type", subjectType,
+		"failed_attempts", throttle.FailedAttempts,
+		"locked_until", lockedUntil,
+	)
+}
+
+// recordIPFailure counts a failed login against the client IP, if known
+func (s *svc) recordIPFailure(ctx context.Context, clientIP string) {
+	if clientIP == "" {
+		return
+	}
+	s.recordLoginFailure(ctx, throttleIP, clientIP, s.lockout.IPThreshold, nil, clientIP)
+}
+
+// UnlockUser clears an account lockout and its failed-attempt counter
+func (s *svc) UnlockUser(ctx context.Context, id uuid.UUID) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	event := LockoutEvent{
+		Action:      lockoutActionUnlocked,
+		SubjectType: throttleUser,
+		Subject:     user.ID.String(),
+		UserID:      &user.ID,
+	}
+	if err := s.repo.ClearLoginThrottle(ctx, throttleUser, user.ID.String(), &event); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// mfaChallengeTTL is how long a correct password waits for its second factor
+const mfaChallengeTTL = 5 * time.Minute
+
+// Recovery code settings
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10 // 16 base32 characters, shown as xxxx-xxxx-xxxx-xxxx
+)
+
+// recoveryCodeEncoding avoids padding and is case-insensitive once lowered
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPCredential is a user's stored authenticator
+type TOTPCredential struct {
+	UserID           uuid.UUID
+	SecretCiphertext []byte
+	ConfirmedAt      *time.Time
+	LastUsedStep     *int64
+}
+
+// EnrollTOTP creates a new, unconfirmed TOTP secret for the user, replacing
+// any earlier unconfirmed one. The secret is only returned here, so only
+// the account owner may enroll.
+func (s *svc) EnrollTOTP(ctx context.Context, id uuid.UUID) (*TOTPEnrollmentResponse, error) {
+	if err := authorizeOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if s.secrets == nil {
+		return nil, ErrMFAUnavailable
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	ciphertext, err := s.secrets.seal(secret, user.ID[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	if err := s.repo.SavePendingTOTP(ctx, user.ID, ciphertext); err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &TOTPEnrollmentResponse{
+		Secret:     totpEncoding.EncodeToString(secret),
+		OTPAuthURI: totpURI(s.totp.Issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP activates a pending TOTP secret once the user proves their
+// authenticator produces matching codes, and issues the first recovery codes
+// to the account owner
+func (s *svc) ConfirmTOTP(ctx context.Context, id uuid.UUID, req ConfirmTOTPRequest) (*RecoveryCodesResponse, error) {
+	if err := authorizeOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	credential, secret, err := s.loadTOTP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totpMatch(secret, req.Code, time.Now(), s.totp.Skew)
+	if !ok {
+		return nil, &ValidationError{Field: "code", Rule: "totp", Message: "does not match your authenticator app"}
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ConfirmTOTP(ctx, id, step, hashes); err != nil {
+		return nil, fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// issueMFAChallenge stores a challenge for a user whose password was just
+// verified and returns it. Only its hash is kept.
+func (s *svc) issueMFAChallenge(ctx context.Context, user *User) (string, error) {
+	challenge, challengeHash, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.CreateMFAChallenge(ctx, user.ID, challengeHash, time.Now().Add(mfaChallengeTTL)); err != nil {
+		return "", fmt.Errorf("failed to store MFA challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// VerifyMFA finishes a login started by Authenticate. The challenge it
+// returned names the user, so codes can only be submitted after the password
+// and never for an arbitrary account. code may be a current authenticator
+// code or an unused recovery code. Accepted TOTP steps, recovery codes and
+// challenges cannot be used again. Failures count towards the account
+// lockout, so codes cannot be brute forced; only a passed second factor
+// clears it and records the login.
+func (s *svc) VerifyMFA(ctx context.Context, req VerifyMFARequest, clientIP string) (*MFAVerificationResponse, error) {
+	challengeHash := hashToken(req.Challenge)
+	id, err := s.repo.GetMFAChallengeUser(ctx, challengeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up MFA challenge: %w", err)
+	}
+
+	if err := s.checkLocked(ctx, throttleUser, id.String()); err != nil {
+		return nil, err
+	}
+
+	credential, secret, err := s.loadTOTP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if credential.ConfirmedAt == nil {
+		return nil, ErrMFANotEnrolled
+	}
+
+	response, err := s.verifySecondFactor(ctx, id, secret, req.Code)
+	if errors.Is(err, ErrInvalidMFACode) {
+		s.recordLoginFailure(ctx, throttleUser, id.String(), s.lockout.UserThreshold, &id, clientIP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Concurrent verifications cannot both finish the same login
+	if _, err := s.repo.ConsumeMFAChallenge(ctx, challengeHash); err != nil {
+		return nil, fmt.Errorf("failed to redeem MFA challenge: %w", err)
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.completeLogin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	userResponse := user.ToResponse()
+	response.User = &userResponse
+	return response, nil
+}
+
+// verifySecondFactor dispatches on the shape of code: all digits is a TOTP
+// code, anything else is treated as a recovery code
+func (s *svc) verifySecondFactor(ctx context.Context, id uuid.UUID, secret []byte, code string) (*MFAVerificationResponse, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totpDigits && strings.Trim(code, "0123456789") == "" {
+		step, ok := totpMatch(secret, code, time.Now(), s.totp.Skew)
+		if !ok {
+			return nil, ErrInvalidMFACode
+		}
+		// Rejects a step at or before the last one accepted (replay)
+		if err := s.repo.UseTOTPStep(ctx, id, step); err != nil {
+			return nil, fmt.Errorf("failed to record TOTP step: %w", err)
+		}
+		return &MFAVerificationResponse{Method: "totp"}, nil
+	}
+
+	remaining, err := s.repo.ConsumeRecoveryCode(ctx, id, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	return &MFAVerificationResponse{Method: "recovery_code", RecoveryCodesRemaining: &remaining}, nil
+}
+
+// RegenerateRecoveryCodes replaces all of a user's recovery codes.
+// Only the account owner may see the new codes.
+func (s *svc) RegenerateRecoveryCodes(ctx context.Context, id uuid.UUID) (*RecoveryCodesResponse, error) {
+	if err := authorizeOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	credential, err := s.repo.GetTOTP(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+	if credential.ConfirmedAt == nil {
+		return nil, ErrMFANotEnrolled
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, id, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// loadTOTP fetches and decrypts a user's TOTP secret
+func (s *svc) loadTOTP(ctx context.Context, id uuid.UUID) (*TOTPCredential, []byte, error) {
+	if s.secrets == nil {
+		return nil, nil, ErrMFAUnavailable
+	}
+
+	credential, err := s.repo.GetTOTP(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	secret, err := s.secrets.open(credential.SecretCiphertext, id[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return credential, secret, nil
+}
+
+// newRecoveryCodes returns display-formatted codes and the hashes to store
+func newRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeBytes)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))
+		codes[i] = raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		hashes[i] = hashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts codes typed with any case, spaces or dashes
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
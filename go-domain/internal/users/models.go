@@ -6,6 +6,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserStatus is the lifecycle state of a user account
+type UserStatus string
+
+const (
+	StatusActive    UserStatus = "active"    // normal account
+	StatusSuspended UserStatus = "suspended" // blocked by an admin, keeps its username and email
+	StatusDeleted   UserStatus = "deleted"   // soft deleted, releases its username and email
+)
+
+// Valid reports whether s is a known status
+func (s UserStatus) Valid() bool {
+	switch s {
+	case StatusActive, StatusSuspended, StatusDeleted:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
 	ID           uuid.UUID  `json:"id"`
@@ -14,51 +32,281 @@ type User struct {
 	PasswordHash string     `json:"-"` // Never expose password hash in JSON
 	FirstName    *string    `json:"first_name,omitempty"`
 	LastName     *string    `json:"last_name,omitempty"`
-	IsActive     bool       `json:"is_active"`
+	Status       UserStatus `json:"status"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	// PasswordChangedAt lets the BFF reject sessions issued before the last change
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	// EmailVerifiedAt is nil until the current email has been confirmed
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// MFAEnabledAt is when TOTP was confirmed, nil without a second factor
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty"`
+	// Version increments on every write and is exposed as the ETag
+	Version int `json:"-"`
+}
+
+// UserVersion is a past or current state of a user, valid from ValidFrom
+// until ValidTo. Versions are written by a trigger on the users table and
+// numbered by Revision from 1 per user. Password hashes and login times are
+// not kept.
+type UserVersion struct {
+	UserID            uuid.UUID
+	Revision          int // not the ETag Version of User
+	Username          string
+	Email             string
+	FirstName         *string
+	LastName          *string
+	Status            UserStatus
+	EmailVerifiedAt   *time.Time
+	PasswordChangedAt time.Time
+	DeletedAt         *time.Time
+	CreatedAt         time.Time
+	ValidFrom         time.Time
+	ValidTo           *time.Time // nil for the current version
+}
+
+// ListFilter narrows the users returned by List and Count.
+// Zero values do not filter. Date ranges include After and exclude Before.
+type ListFilter struct {
+	Statuses      []UserStatus // defaults to active users only when empty
+	Verified      *bool        // nil matches both verified and unverified emails
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	NamePrefix    string // case-insensitive prefix of the username, first or last name
+	EmailPrefix   string // case-insensitive prefix of the email
+}
+
+// PageRequest selects a page of users: by Cursor when set, else by Offset
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string // token from a previous page's next_cursor or prev_cursor
+}
+
+// UserPage is one page of a user listing. The cursors are empty when there
+// are no users in that direction.
+type UserPage struct {
+	Users      []*UserResponse
+	Total      int64 // users matching the filter across all pages
+	NextCursor string
+	PrevCursor string
+}
+
+// SearchQuery is a validated free-text search for users
+type SearchQuery struct {
+	Text   string     // what the caller typed, e.g. part of a name or a misspelled email
+	Filter ListFilter // narrows the matches like in ListUsers
+	Limit  int
+	Offset int
+}
+
+// UserSearchHit is a user matching a search
+type UserSearchHit struct {
+	User *User
+	// Rank orders hits that matched the same way, higher first
+	Rank float64
+	// Exact is true for full-text matches and false for users only found by
+	// trigram similarity, which are ranked after every full-text match
+	Exact bool
+	// Highlights maps json field names to their HTML-escaped value with the
+	// matched words wrapped in <mark> tags; fields without a match are omitted
+	Highlights map[string]string
 }
 
 // CreateUserRequest represents the data needed to create a new user
+// Custom rules (username, not_reserved) are registered in validation.go
 type CreateUserRequest struct {
-	Username  string  `json:"username" binding:"required,min=3,max=255"`
-	Email     string  `json:"email" binding:"required,email"`
-	Password  string  `json:"password" binding:"required,min=8"`
-	FirstName *string `json:"first_name,omitempty"`
-	LastName  *string `json:"last_name,omitempty"`
+	Username  string  `json:"username" binding:"required,min=3,max=255,username,not_reserved"`
+	Email     string  `json:"email" binding:"required,email,max=255"`
+	Password  string  `json:"password" binding:"required,max=1024"` // strength rules live in PasswordPolicy
+	FirstName *string `json:"first_name,omitempty" binding:"omitnil,max=255"`
+	LastName  *string `json:"last_name,omitempty" binding:"omitnil,max=255"`
 }
 
-// UpdateUserRequest represents the data that can be updated for a user
+// UpdateUserRequest represents the data that can be updated for a user.
+// omitnil skips absent fields but still validates explicit empty strings.
 type UpdateUserRequest struct {
-	Username  *string `json:"username,omitempty"`
-	Email     *string `json:"email,omitempty"`
-	FirstName *string `json:"first_name,omitempty"`
-	LastName  *string `json:"last_name,omitempty"`
-	IsActive  *bool   `json:"is_active,omitempty"`
+	Username  *string `json:"username,omitempty" binding:"omitnil,min=3,max=255,username,not_reserved"`
+	Email     *string `json:"email,omitempty" binding:"omitnil,email,max=255"`
+	FirstName *string `json:"first_name,omitempty" binding:"omitnil,max=255"`
+	LastName  *string `json:"last_name,omitempty" binding:"omitnil,max=255"`
+	IsActive  *bool   `json:"is_active,omitempty"` // false suspends, true reactivates a suspended user
+}
+
+// AuthenticateRequest represents a credential check forwarded by the BFF.
+// Login may be either a username or an email address.
+type AuthenticateRequest struct {
+	Login    string `json:"login" binding:"required,max=255"`
+	Password string `json:"password" binding:"required,max=1024"`
+}
+
+// ChangePasswordRequest represents a user changing their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=1024"`
+	NewPassword     string `json:"new_password" binding:"required,max=1024"`
+}
+
+// ResetPasswordRequest represents an admin setting a user's password
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required,max=1024"`
+}
+
+// PasswordResetRequest asks for a reset link to be sent to an email address
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// ConfirmPasswordResetRequest redeems a reset token
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required,max=255"`
+	NewPassword string `json:"new_password" binding:"required,max=1024"`
+}
+
+// ConfirmEmailVerificationRequest redeems an email verification token
+type ConfirmEmailVerificationRequest struct {
+	Token string `json:"token" binding:"required,max=255"`
+}
+
+// ConfirmTOTPRequest proves a newly enrolled authenticator works
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// VerifyMFARequest finishes a login: the challenge returned by Authenticate
+// and a TOTP code or a recovery code
+type VerifyMFARequest struct {
+	Challenge string `json:"challenge" binding:"required,max=255"`
+	Code      string `json:"code" binding:"required,max=64"`
+}
+
+// TOTPEnrollmentResponse is returned once when enrolling an authenticator
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`      // base32, for manual entry
+	OTPAuthURI string `json:"otpauth_uri"` // rendered as a QR code
+}
+
+// RecoveryCodesResponse lists freshly generated recovery codes. They are
+// stored hashed and cannot be shown again.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAVerificationResponse reports which factor was accepted and who logged in
+type MFAVerificationResponse struct {
+	Method                 string        `json:"method"`                             // "totp" or "recovery_code"
+	RecoveryCodesRemaining *int          `json:"recovery_codes_remaining,omitempty"` // set when a recovery code was used
+	User                   *UserResponse `json:"user"`
+}
+
+// AuthenticateResponse is the outcome of a correct password. Without a
+// second factor the login is complete and User is set; otherwise the login
+// is finished by passing MFAChallenge and a code to VerifyMFA.
+type AuthenticateResponse struct {
+	MFARequired  bool          `json:"mfa_required"`
+	MFAChallenge string        `json:"mfa_challenge,omitempty"`
+	User         *UserResponse `json:"user,omitempty"`
 }
 
 // UserResponse represents the user data returned in API responses
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName *string   `json:"first_name,omitempty"`
-	LastName  *string   `json:"last_name,omitempty"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                uuid.UUID  `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	FirstName         *string    `json:"first_name,omitempty"`
+	LastName          *string    `json:"last_name,omitempty"`
+	IsActive          bool       `json:"is_active"` // true only when status is active
+	Status            UserStatus `json:"status"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+	MFAEnabled        bool       `json:"mfa_enabled"`
+	Version           int        `json:"-"` // sent in the ETag header
 }
 
 // ToResponse converts a User model to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                u.ID,
+		Username:          u.Username,
+		Email:             u.Email,
+		FirstName:         u.FirstName,
+		LastName:          u.LastName,
+		IsActive:          u.Status == StatusActive,
+		Status:            u.Status,
+		CreatedAt:         u.CreatedAt,
+		UpdatedAt:         u.UpdatedAt,
+		DeletedAt:         u.DeletedAt,
+		LastLoginAt:       u.LastLoginAt,
+		PasswordChangedAt: u.PasswordChangedAt,
+		EmailVerifiedAt:   u.EmailVerifiedAt,
+		MFAEnabled:        u.MFAEnabledAt != nil,
+		Version:           u.Version,
+	}
+}
+
+// UserVersionResponse represents a user version returned in API responses
+type UserVersionResponse struct {
+	Revision          int        `json:"revision"`
+	ValidFrom         time.Time  `json:"valid_from"`
+	ValidTo           *time.Time `json:"valid_to,omitempty"` // omitted for the current version
+	ID                uuid.UUID  `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	FirstName         *string    `json:"first_name,omitempty"`
+	LastName          *string    `json:"last_name,omitempty"`
+	IsActive          bool       `json:"is_active"`
+	Status            UserStatus `json:"status"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+}
+
+// ToResponse converts a UserVersion model to UserVersionResponse
+func (v *UserVersion) ToResponse() UserVersionResponse {
+	return UserVersionResponse{
+		Revision:          v.Revision,
+		ValidFrom:         v.ValidFrom,
+		ValidTo:           v.ValidTo,
+		ID:                v.UserID,
+		Username:          v.Username,
+		Email:             v.Email,
+		FirstName:         v.FirstName,
+		LastName:          v.LastName,
+		IsActive:          v.Status == StatusActive,
+		Status:            v.Status,
+		CreatedAt:         v.CreatedAt,
+		DeletedAt:         v.DeletedAt,
+		PasswordChangedAt: v.PasswordChangedAt,
+		EmailVerifiedAt:   v.EmailVerifiedAt,
+	}
+}
+
+// UserSearchResult represents a search hit returned in API responses
+type UserSearchResult struct {
+	UserResponse
+	Rank       float64           `json:"rank"`
+	Match      string            `json:"match"` // "text" for full-text matches, "similar" for typos
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// ToResponse converts a UserSearchHit model to UserSearchResult
+func (h *UserSearchHit) ToResponse() UserSearchResult {
+	match := "similar"
+	if h.Exact {
+		match = "text"
+	}
+	return UserSearchResult{
+		UserResponse: h.User.ToResponse(),
+		Rank:         h.Rank,
+		Match:        match,
+		Highlights:   h.Highlights,
 	}
 }
@@ -0,0 +1,33 @@
+package users
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Usernames and emails are stored in citext columns, so case is ignored by
+// the database. NFKC folds compatibility characters (e.g. fullwidth letters)
+// so visually identical identities compare equal as well.
+
+// normalizeUsername trims and NFKC-normalizes a username, keeping its case
+func normalizeUsername(username string) string {
+	return norm.NFKC.String(strings.TrimSpace(username))
+}
+
+// normalizeEmail trims, NFKC-normalizes and lower-cases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(norm.NFKC.String(strings.TrimSpace(email)))
+}
+
+// normalizeName trims an optional display name, mapping blank values to nil
+func normalizeName(name *string) *string {
+	if name == nil {
+		return nil
+	}
+	trimmed := norm.NFKC.String(strings.TrimSpace(*name))
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
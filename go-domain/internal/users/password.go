@@ -0,0 +1,88 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// hashPassword hashes a plaintext password for storage.
+// Every code path that sets a password must go through here.
+func (s *svc) hashPassword(password string) (string, error) {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hash, nil
+}
+
+// passwordMatches reports whether password matches the stored hash.
+// Unreadable hashes are logged and treated as a mismatch.
+func (s *svc) passwordMatches(user *User, password string) (match bool, needsRehash bool) {
+	match, needsRehash, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		slog.Error("Failed to verify password hash", "error", err, "user_id", user.ID)
+		return false, false
+	}
+	return match, needsRehash
+}
+
+// ChangePassword sets a new password after verifying the current one.
+// Only the account owner may call it. Wrong current passwords count towards
+// the account lockout like failed logins, so it cannot be used to guess.
+func (s *svc) ChangePassword(ctx context.Context, id uuid.UUID, req ChangePasswordRequest, clientIP string) (*UserResponse, error) {
+	if err := authorizeOwner(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.checkLocked(ctx, throttleUser, id.String()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if match, _ := s.passwordMatches(user, req.CurrentPassword); !match {
+		s.recordLoginFailure(ctx, throttleUser, user.ID.String(), s.lockout.UserThreshold, &user.ID, clientIP)
+		return nil, &ValidationError{Field: "current_password", Rule: "match", Message: "is incorrect"}
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, &ValidationError{Field: "new_password", Rule: "nefield", Message: "must differ from the current password"}
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword)
+}
+
+// ResetPassword sets a new password without the current one (admin path)
+func (s *svc) ResetPassword(ctx context.Context, id uuid.UUID, req ResetPasswordRequest) (*UserResponse, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.setPassword(ctx, user, req.NewPassword)
+}
+
+// setPassword checks the password policy, then hashes and stores a new
+// password, bumping password_changed_at
+func (s *svc) setPassword(ctx context.Context, user *User, password string) (*UserResponse, error) {
+	if err := s.policy.Check("new_password", password, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = hash
+	if err := s.repo.UpdatePassword(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
@@ -0,0 +1,104 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// passwordResetTokenTTL is how long an emailed reset link stays valid
+	passwordResetTokenTTL = time.Hour
+
+	// passwordResetLimit caps reset emails per account within passwordResetWindow
+	passwordResetLimit  = 3
+	passwordResetWindow = time.Hour
+)
+
+// RequestPasswordReset issues a reset token for the account with this email
+// and publishes it for the mailer. Unknown, inactive and rate limited
+// accounts are silently ignored so the response never reveals whether an
+// email is registered.
+func (s *svc) RequestPasswordReset(ctx context.Context, req PasswordResetRequest) error {
+	user, err := s.repo.GetByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status != StatusActive {
+		return nil
+	}
+
+	recent, err := s.repo.CountPasswordResetTokensSince(ctx, user.ID, time.Now().Add(-passwordResetWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count reset tokens: %w", err)
+	}
+	if recent >= passwordResetLimit {
+		slog.WarnContext(ctx, "Password reset rate limited", "user_id", user.ID)
+		return nil
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(passwordResetTokenTTL)
+	if err := s.repo.CreatePasswordResetToken(ctx, user.ID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	event := Event{
+		Type:       EventPasswordResetRequested,
+		UserID:     user.ID,
+		OccurredAt: time.Now(),
+		Data: map[string]string{
+			"email":      user.Email,
+			"token":      token,
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+	}
+	if err := s.events.Publish(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish reset event: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmPasswordReset redeems a reset token and sets the new password.
+// The password policy is checked before the token is spent, so a rejected
+// password leaves the link usable. Redeeming the token, storing the password
+// and invalidating the user's other links happen in one transaction, so
+// concurrent redemptions cannot both succeed.
+func (s *svc) ConfirmPasswordReset(ctx context.Context, req ConfirmPasswordResetRequest) error {
+	tokenHash := hashToken(req.Token)
+	userID, err := s.repo.GetPasswordResetTokenUser(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to look up reset token: %w", err)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.policy.Check("new_password", req.NewPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = hash
+	if err := s.repo.ResetPassword(ctx, tokenHash, user); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	return nil
+}
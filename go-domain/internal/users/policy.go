@@ -0,0 +1,167 @@
+package users
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicyConfig configures the rules new passwords must satisfy
+type PasswordPolicyConfig struct {
+	MinLength     int  // minimum length in characters
+	RequireUpper  bool // at least one upper-case letter
+	RequireLower  bool // at least one lower-case letter
+	RequireDigit  bool // at least one digit
+	RequireSymbol bool // at least one character that is not a letter or digit
+
+	// BreachedPasswordsDir holds Have I Been Pwned range files: one file per
+	// 5 character upper-case SHA-1 prefix, each line "SUFFIX:COUNT".
+	// The check is skipped when empty.
+	BreachedPasswordsDir string
+}
+
+// DefaultPasswordPolicyConfig follows NIST SP 800-63B: length over composition
+func DefaultPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength: 8,
+	}
+}
+
+// PolicyViolation is one reason a password was rejected
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a password broke
+type PasswordPolicyError struct {
+	Field      string // json name of the password field
+	Violations []PolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = v.Rule
+	}
+	return fmt.Sprintf("%s violates password policy: %s", e.Field, strings.Join(rules, ", "))
+}
+
+// Is lets errors.Is(err, ErrValidation) match a *PasswordPolicyError
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// PasswordPolicy checks candidate passwords against the configured rules
+type PasswordPolicy struct {
+	cfg PasswordPolicyConfig
+}
+
+// NewPasswordPolicy creates a password policy
+func NewPasswordPolicy(cfg PasswordPolicyConfig) *PasswordPolicy {
+	return &PasswordPolicy{
+		cfg: cfg,
+	}
+}
+
+// Check returns a *PasswordPolicyError for field if password breaks any rule.
+// username and email are the account's identity and may not appear in the password.
+func (p *PasswordPolicy) Check(field, password, username, email string) error {
+	var violations []PolicyViolation
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("must be at least %d characters", p.cfg.MinLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !hasUpper {
+		violations = append(violations, PolicyViolation{Rule: "uppercase", Message: "must contain an upper-case letter"})
+	}
+	if p.cfg.RequireLower && !hasLower {
+		violations = append(violations, PolicyViolation{Rule: "lowercase", Message: "must contain a lower-case letter"})
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		violations = append(violations, PolicyViolation{Rule: "digit", Message: "must contain a digit"})
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		violations = append(violations, PolicyViolation{Rule: "symbol", Message: "must contain a symbol"})
+	}
+
+	lowered := strings.ToLower(password)
+	if banned := strings.ToLower(username); len(banned) >= 3 && strings.Contains(lowered, banned) {
+		violations = append(violations, PolicyViolation{Rule: "contains_username", Message: "must not contain your username"})
+	}
+	if local, _, _ := strings.Cut(strings.ToLower(email), "@"); len(local) >= 3 && strings.Contains(lowered, local) {
+		violations = append(violations, PolicyViolation{Rule: "contains_email", Message: "must not contain your email address"})
+	}
+
+	if p.isBreached(password) {
+		violations = append(violations, PolicyViolation{
+			Rule:    "breached",
+			Message: "has appeared in a data breach, please choose another",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Field: field, Violations: violations}
+	}
+	return nil
+}
+
+// isBreached looks the password's SHA-1 up in the local range files. Only the
+// file for the 5 character prefix is read, the same k-anonymity split the
+// Pwned Passwords API uses. Read errors are logged and fail open.
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if p.cfg.BreachedPasswordsDir == "" {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	file, err := os.Open(filepath.Join(p.cfg.BreachedPasswordsDir, prefix))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Error("Failed to open breached password range", "error", err, "prefix", prefix)
+		}
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		candidate, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(candidate), suffix) {
+			return true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Error("Failed to read breached password range", "error", err, "prefix", prefix)
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package users
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	// A range file as published by Have I Been Pwned, holding
+	// SHA-1("password") = 5BAA6 1E4C9B93F3F0682250B6CF8331B7EE68FD8
+	breached := t.TempDir()
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n"
+	if err := os.WriteFile(filepath.Join(breached, "5BAA6"), []byte(rangeFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	composition := PasswordPolicyConfig{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name      string
+		cfg       PasswordPolicyConfig
+		password  string
+		wantRules []string // nil when the password is accepted
+	}{
+		{name: "long enough", cfg: DefaultPasswordPolicyConfig(), password: "correct horse"},
+		{name: "too short", cfg: DefaultPasswordPolicyConfig(), password: "short", wantRules: []string{"min_length"}},
+		{name: "length counts characters, not bytes", cfg: DefaultPasswordPolicyConfig(), password: "ääääääää"},
+		{name: "every class present", cfg: composition, password: "Correct-h0rse"},
+		{name: "every class missing", cfg: composition, password: "        ", wantRules: []string{"uppercase", "lowercase", "digit"}},
+		{name: "only lower-case", cfg: composition, password: "correcthorse", wantRules: []string{"uppercase", "digit", "symbol"}},
+		{name: "contains username", cfg: DefaultPasswordPolicyConfig(), password: "xxALICExx", wantRules: []string{"contains_username"}},
+		{name: "contains email", cfg: DefaultPasswordPolicyConfig(), password: "liddell-1865", wantRules: []string{"contains_email"}},
+		{name: "breached", cfg: PasswordPolicyConfig{MinLength: 8, BreachedPasswordsDir: breached}, password: "password", wantRules: []string{"breached"}},
+		{name: "not breached", cfg: PasswordPolicyConfig{MinLength: 8, BreachedPasswordsDir: breached}, password: "correct horse"},
+		{name: "breached list not configured", cfg: DefaultPasswordPolicyConfig(), password: "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewPasswordPolicy(tt.cfg).Check("new_password", tt.password, "alice", "liddell@example.com")
+			if tt.wantRules == nil {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			if !errors.As(err, &policyErr) || !errors.Is(err, ErrValidation) || policyErr.Field != "new_password" {
+				t.Fatalf("Check() error = %v, want a new_password *PasswordPolicyError", err)
+			}
+			var rules []string
+			for _, v := range policyErr.Violations {
+				rules = append(rules, v.Rule)
+			}
+			if !slices.Equal(rules, tt.wantRules) {
+				t.Errorf("Check() rules = %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sensitiveAuditFields are reported as changed without their values
+var sensitiveAuditFields = []string{"password_hash"}
+
+// lockUser loads a user inside tx and locks the row until the transaction
+// ends, giving a stable "before" snapshot for the audit diff
+func lockUser(ctx context.Context, tx pgx.Tx, id uuid.UUID, includeDeleted bool) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND ($2 OR deleted_at IS NULL) FOR UPDATE`
+
+	user, err := scanUser(tx.QueryRow(ctx, query, id, includeDeleted))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	return user, nil
+}
+
+// recordUserChange writes an audit event for a user write inside tx.
+// before is nil for new users. Writes that change nothing are not recorded.
+func recordUserChange(ctx context.Context, tx pgx.Tx, action string, before, after *User) error {
+	var old map[string]any
+	if before != nil {
+		old = auditFields(before)
+	}
+
+	changes := audit.Diff(old, auditFields(after), sensitiveAuditFields...)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return audit.Record(ctx, tx, audit.Entry{
+		EntityType: audit.EntityUser,
+		EntityID:   after.ID,
+		Action:     action,
+		Changes:    changes,
+	})
+}
+
+// auditFields is the audited view of a user, keyed by json field name.
+// Timestamps maintained by the database on every write are left out.
+func auditFields(u *User) map[string]any {
+	return map[string]any{
+		"username":          u.Username,
+		"email":             u.Email,
+		"password_hash":     u.PasswordHash,
+		"first_name":        optional(u.FirstName),
+		"last_name":         optional(u.LastName),
+		"status":            string(u.Status),
+		"email_verified_at": optionalTime(u.EmailVerifiedAt),
+		"deleted_at":        optionalTime(u.DeletedAt),
+	}
+}
+
+// optional dereferences p, mapping nil to an untyped nil
+func optional(p *string) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// optionalTime formats t in UTC so snapshots compare by instant
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
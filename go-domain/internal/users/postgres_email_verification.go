@@ -0,0 +1,115 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/audit"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateEmailVerificationToken stores the hash of a token sent to email
+func (r *postgresRepository) CreateEmailVerificationToken(ctx context.Context, userID uuid.UUID, email string, tokenHash []byte, expiresAt time.Time) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID, email, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+
+	return nil
+}
+
+// CountEmailVerificationTokensSince counts tokens issued to a user after since
+func (r *postgresRepository) CountEmailVerificationTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM email_verification_tokens
+		WHERE user_id = $1 AND created_at > $2
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count verification tokens: %w", err)
+	}
+
+	return count, nil
+}
+
+// ConsumeEmailVerificationToken marks an unused, unexpired token as used and
+// returns its user and the address it was sent to
+func (r *postgresRepository) ConsumeEmailVerificationToken(ctx context.Context, tokenHash []byte) (uuid.UUID, string, error) {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email
+	`
+
+	var userID uuid.UUID
+	var email string
+	if err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID, &email); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, "", ErrInvalidToken
+		}
+		return uuid.Nil, "", fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	return userID, email, nil
+}
+
+// InvalidateEmailVerificationTokens marks all of a user's outstanding tokens as used
+func (r *postgresRepository) InvalidateEmailVerificationTokens(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified stamps email_verified_at if the user still has this email
+// and records the change in the audit log in the same transaction.
+// Returns ErrInvalidToken if the email has changed or the user was deleted.
+func (r *postgresRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (*User, error) {
+	var user *User
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		before, err := lockUser(ctx, tx, userID, false)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		query := `
+			UPDATE users
+			SET email_verified_at = NOW(), updated_at = NOW(), version = version + 1
+			WHERE id = $1 AND email = $2
+			RETURNING ` + userColumns
+
+		user, err = scanUser(tx.QueryRow(ctx, query, userID, email))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidToken
+			}
+			return fmt.Errorf("failed to mark email verified: %w", err)
+		}
+
+		return recordUserChange(ctx, tx, audit.ActionEmailVerified, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,125 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// versionColumns is the column list read by scanVersion, in scan order
+const versionColumns = `user_id, revision, username, email, first_name, last_name,
+	status, email_verified_at, password_changed_at, deleted_at, created_at,
+	valid_from, valid_to`
+
+// ListHistory retrieves a user's versions, newest first
+func (r *postgresRepository) ListHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*UserVersion, error) {
+	// Set default limit if not provided
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT ` + versionColumns + `
+		FROM users_history
+		WHERE user_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*UserVersion{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user history: %w", err)
+	}
+
+	return versions, nil
+}
+
+// CountHistory returns the number of versions recorded for a user
+func (r *postgresRepository) CountHistory(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM users_history WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count user history: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetRevision retrieves the version of a user with the given revision number
+func (r *postgresRepository) GetRevision(ctx context.Context, userID uuid.UUID, revision int) (*UserVersion, error) {
+	query := `SELECT ` + versionColumns + ` FROM users_history WHERE user_id = $1 AND revision = $2`
+	return r.getVersion(ctx, query, userID, revision)
+}
+
+// GetVersionAt retrieves the version that was current at time at. A version
+// is current from valid_from (inclusive) until valid_to (exclusive).
+func (r *postgresRepository) GetVersionAt(ctx context.Context, userID uuid.UUID, at time.Time) (*UserVersion, error) {
+	query := `
+		SELECT ` + versionColumns + `
+		FROM users_history
+		WHERE user_id = $1
+		  AND valid_from <= $2
+		  AND (valid_to IS NULL OR valid_to > $2)
+		ORDER BY revision DESC
+		LIMIT 1
+	`
+	return r.getVersion(ctx, query, userID, at)
+}
+
+// getVersion runs a single-row version query and maps no rows to ErrRevisionNotFound
+func (r *postgresRepository) getVersion(ctx context.Context, query string, args ...any) (*UserVersion, error) {
+	version, err := scanVersion(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get user version: %w", err)
+	}
+
+	return version, nil
+}
+
+// scanVersion scans a row selected with versionColumns
+func scanVersion(row pgx.Row) (*UserVersion, error) {
+	version := &UserVersion{}
+	err := row.Scan(
+		&version.UserID,
+		&version.Revision,
+		&version.Username,
+		&version.Email,
+		&version.FirstName,
+		&version.LastName,
+		&version.Status,
+		&version.EmailVerifiedAt,
+		&version.PasswordChangedAt,
+		&version.DeletedAt,
+		&version.CreatedAt,
+		&version.ValidFrom,
+		&version.ValidTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
@@ -0,0 +1,119 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetLoginThrottle returns the failed-login state of a subject.
+// Subjects without failures get an empty throttle.
+func (r *postgresRepository) GetLoginThrottle(ctx context.Context, subjectType, subject string) (*LoginThrottle, error) {
+	query := `
+		SELECT failed_attempts, lockouts, last_failed_at, locked_until
+		FROM login_throttles
+		WHERE subject_type = $1 AND subject = $2
+	`
+
+	throttle := &LoginThrottle{SubjectType: subjectType, Subject: subject}
+	err := r.db.QueryRow(ctx, query, subjectType, subject).Scan(
+		&throttle.FailedAttempts,
+		&throttle.Lockouts,
+		&throttle.LastFailedAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get login throttle: %w", err)
+	}
+
+	return throttle, nil
+}
+
+// RecordLoginFailure atomically increments the failure counter. Counting
+// restarts at 1 when the previous failure is older than window.
+func (r *postgresRepository) RecordLoginFailure(ctx context.Context, subjectType, subject string, window time.Duration) (*LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttles (subject_type, subject, failed_attempts, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (subject_type, subject) DO UPDATE
+		SET failed_attempts = CASE
+		        WHEN login_throttles.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+		        ELSE login_throttles.failed_attempts + 1
+		    END,
+		    last_failed_at = NOW()
+		RETURNING failed_attempts, lockouts, last_failed_at, locked_until
+	`
+
+	throttle := &LoginThrottle{SubjectType: subjectType, Subject: subject}
+	err := r.db.QueryRow(ctx, query, subjectType, subject, window.Seconds()).Scan(
+		&throttle.FailedAttempts,
+		&throttle.Lockouts,
+		&throttle.LastFailedAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return throttle, nil
+}
+
+// LockLoginSubject locks the event's subject until event.LockedUntil and
+// appends the event to the lockout audit trail in the same transaction
+func (r *postgresRepository) LockLoginSubject(ctx context.Context, event LockoutEvent) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			UPDATE login_throttles
+			SET locked_until = $3, lockouts = lockouts + 1, failed_attempts = 0
+			WHERE subject_type = $1 AND subject = $2
+		`
+		if _, err := tx.Exec(ctx, query, event.SubjectType, event.Subject, event.LockedUntil); err != nil {
+			return fmt.Errorf("failed to lock login subject: %w", err)
+		}
+
+		return insertLockoutEvent(ctx, tx, event)
+	})
+}
+
+// ClearLoginThrottle removes a subject's failure state. When event is set it
+// is appended to the lockout audit trail in the same transaction.
+func (r *postgresRepository) ClearLoginThrottle(ctx context.Context, subjectType, subject string, event *LockoutEvent) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `DELETE FROM login_throttles WHERE subject_type = $1 AND subject = $2`
+		if _, err := tx.Exec(ctx, query, subjectType, subject); err != nil {
+			return fmt.Errorf("failed to clear login throttle: %w", err)
+		}
+
+		if event == nil {
+			return nil
+		}
+		return insertLockoutEvent(ctx, tx, *event)
+	})
+}
+
+// insertLockoutEvent appends to login_lockout_events
+func insertLockoutEvent(ctx context.Context, tx pgx.Tx, event LockoutEvent) error {
+	query := `
+		INSERT INTO login_lockout_events
+			(action, subject_type, subject, user_id, client_ip, failed_attempts, locked_until)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		event.Action,
+		event.SubjectType,
+		event.Subject,
+		event.UserID,
+		event.ClientIP,
+		event.FailedAttempts,
+		event.LockedUntil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert lockout event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetTOTP returns a user's TOTP credential, confirmed or not.
+// Returns ErrMFANotEnrolled if the user never enrolled.
+func (r *postgresRepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*TOTPCredential, error) {
+	query := `
+		SELECT user_id, secret_ciphertext, confirmed_at, last_used_step
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	credential := &TOTPCredential{}
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&credential.UserID,
+		&credential.SecretCiphertext,
+		&credential.ConfirmedAt,
+		&credential.LastUsedStep,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	return credential, nil
+}
+
+// SavePendingTOTP stores an unconfirmed secret, replacing an earlier
+// unconfirmed one. Returns ErrMFAAlreadyEnabled if TOTP is already confirmed.
+func (r *postgresRepository) SavePendingTOTP(ctx context.Context, userID uuid.UUID, ciphertext []byte) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret_ciphertext)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_ciphertext = EXCLUDED.secret_ciphertext, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, ciphertext)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// ConfirmTOTP marks the pending secret confirmed, records step as used and
+// stores the first set of recovery codes in one transaction
+func (r *postgresRepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes [][]byte) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			UPDATE user_totp
+			SET confirmed_at = NOW(), last_used_step = $2
+			WHERE user_id = $1 AND confirmed_at IS NULL
+		`
+
+		result, err := tx.Exec(ctx, query, userID, step)
+		if err != nil {
+			return fmt.Errorf("failed to confirm TOTP: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			// Confirmed concurrently
+			return ErrMFAAlreadyEnabled
+		}
+
+		// mfa_enabled is part of the user representation, so its ETag changes
+		if _, err := tx.Exec(ctx, `UPDATE users SET version = version + 1 WHERE id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to bump user version: %w", err)
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// UseTOTPStep records step as the newest accepted code.
+// Returns ErrInvalidMFACode if that step (or a later one) was already used.
+func (r *postgresRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1
+		  AND confirmed_at IS NOT NULL
+		  AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use TOTP step: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes all of a user's recovery codes and stores new ones
+func (r *postgresRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes [][]byte) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// ConsumeRecoveryCode marks an unused code as used and returns how many remain.
+// Returns ErrInvalidMFACode if the code is unknown or already used.
+func (r *postgresRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) (int, error) {
+	// The CTE's update is invisible to the outer SELECT, so the consumed
+	// row is excluded from the remaining count by id
+	query := `
+		WITH used AS (
+			UPDATE user_recovery_codes
+			SET used_at = NOW()
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			RETURNING id
+		)
+		SELECT
+			EXISTS (SELECT 1 FROM used),
+			(SELECT COUNT(*) FROM user_recovery_codes
+			 WHERE user_id = $1 AND used_at IS NULL AND id NOT IN (SELECT id FROM used))
+	`
+
+	var consumed bool
+	var remaining int
+	if err := r.db.QueryRow(ctx, query, userID, codeHash).Scan(&consumed, &remaining); err != nil {
+		return 0, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	if !consumed {
+		return 0, ErrInvalidMFACode
+	}
+
+	return remaining, nil
+}
+
+// CreateMFAChallenge stores the hash of a newly issued MFA challenge
+func (r *postgresRepository) CreateMFAChallenge(ctx context.Context, userID uuid.UUID, challengeHash []byte, expiresAt time.Time) error {
+	query := `
+		INSERT INTO mfa_challenges (user_id, challenge_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID, challengeHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return nil
+}
+
+// GetMFAChallengeUser returns the user of an unused, unexpired challenge
+// without consuming it. Returns ErrInvalidToken if no such challenge exists.
+func (r *postgresRepository) GetMFAChallengeUser(ctx context.Context, challengeHash []byte) (uuid.UUID, error) {
+	query := `
+		SELECT user_id FROM mfa_challenges
+		WHERE challenge_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	`
+
+	var userID uuid.UUID
+	if err := r.db.QueryRow(ctx, query, challengeHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+
+	return userID, nil
+}
+
+// ConsumeMFAChallenge marks an unused, unexpired challenge as used and
+// returns its user. Returns ErrInvalidToken if no such challenge exists.
+func (r *postgresRepository) ConsumeMFAChallenge(ctx context.Context, challengeHash []byte) (uuid.UUID, error) {
+	query := `
+		UPDATE mfa_challenges
+		SET used_at = NOW()
+		WHERE challenge_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+
+	var userID uuid.UUID
+	if err := r.db.QueryRow(ctx, query, challengeHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to consume MFA challenge: %w", err)
+	}
+
+	return userID, nil
+}
+
+// replaceRecoveryCodes swaps a user's recovery codes inside tx
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes [][]byte) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_recovery_codes (user_id, code_hash)
+		SELECT $1, unnest($2::bytea[])
+	`
+	if _, err := tx.Exec(ctx, query, userID, codeHashes); err != nil {
+		return fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+
+	return nil
+}
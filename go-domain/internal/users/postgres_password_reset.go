@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreatePasswordResetToken stores the hash of a newly issued reset token
+func (r *postgresRepository) CreatePasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash []byte, expiresAt time.Time) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	return nil
+}
+
+// CountPasswordResetTokensSince counts tokens issued to a user after since
+func (r *postgresRepository) CountPasswordResetTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM password_reset_tokens
+		WHERE user_id = $1 AND created_at > $2
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count reset tokens: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPasswordResetTokenUser returns the user of an unused, unexpired token
+// without consuming it. Returns ErrInvalidToken if no such token exists.
+func (r *postgresRepository) GetPasswordResetTokenUser(ctx context.Context, tokenHash []byte) (uuid.UUID, error) {
+	query := `
+		SELECT user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	`
+
+	var userID uuid.UUID
+	if err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrInvalidToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to get reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// ResetPassword consumes the token, stores user.PasswordHash and marks the
+// user's other outstanding tokens as used in one transaction, so a failed
+// write leaves the link usable and concurrent redemptions cannot both succeed.
+// Returns ErrInvalidToken if the token is unknown, used, expired or issued to
+// another user.
+func (r *postgresRepository) ResetPassword(ctx context.Context, tokenHash []byte, user *User) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			UPDATE password_reset_tokens
+			SET used_at = NOW()
+			WHERE token_hash = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()
+		`
+		result, err := tx.Exec(ctx, query, tokenHash, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrInvalidToken
+		}
+
+		if err := updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+
+		// Any other outstanding links for this account are now stale
+		query = `
+			UPDATE password_reset_tokens
+			SET used_at = NOW()
+			WHERE user_id = $1 AND used_at IS NULL
+		`
+		if _, err := tx.Exec(ctx, query, user.ID); err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,99 @@
+package users
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sortColumns maps sortable fields to their column. Sorting by anything
+// else is rejected, so user input never reaches the ORDER BY clause.
+var sortColumns = map[string]string{
+	SortCreatedAt: "created_at",
+	SortUpdatedAt: "updated_at",
+	SortUsername:  "username",
+	SortEmail:     "email",
+}
+
+// queryArgs collects positional parameters while a query is compiled
+type queryArgs []any
+
+// add appends v and returns its placeholder
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// whereSQL compiles filter into a WHERE clause. Only fixed SQL fragments are
+// written into the query; every value is passed through args.
+func whereSQL(filter ListFilter, args *queryArgs) string {
+	conditions := []string{"status = ANY(" + args.add(statusArgs(filter)) + ")"}
+
+	if filter.Verified != nil {
+		conditions = append(conditions, "(email_verified_at IS NOT NULL) = "+args.add(*filter.Verified))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+args.add(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+args.add(*filter.CreatedBefore))
+	}
+	if filter.UpdatedAfter != nil {
+		conditions = append(conditions, "updated_at >= "+args.add(*filter.UpdatedAfter))
+	}
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, "updated_at < "+args.add(*filter.UpdatedBefore))
+	}
+	if filter.NamePrefix != "" {
+		pattern := args.add(prefixPattern(filter.NamePrefix))
+		conditions = append(conditions, "(lower(username::text) LIKE "+pattern+
+			" OR lower(first_name) LIKE "+pattern+
+			" OR lower(last_name) LIKE "+pattern+")")
+	}
+	if filter.EmailPrefix != "" {
+		conditions = append(conditions, "lower(email::text) LIKE "+args.add(prefixPattern(filter.EmailPrefix)))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// orderSQL compiles sort into an ORDER BY clause, reversed for backward pages
+func orderSQL(sort Sort, reverse bool) (string, error) {
+	column, ok := sortColumns[sort.Field]
+	if !ok {
+		return "", fmt.Errorf("unsortable field %q", sort.Field)
+	}
+
+	direction := "ASC"
+	if sort.Desc != reverse {
+		direction = "DESC"
+	}
+	return "ORDER BY " + column + " " + direction + ", id " + direction, nil
+}
+
+// seekSQL compiles the keyset condition selecting the rows past cursor in
+// the direction it points
+func seekSQL(cursor Cursor, args *queryArgs) (string, error) {
+	column, ok := sortColumns[cursor.Sort.Field]
+	if !ok {
+		return "", fmt.Errorf("unsortable field %q", cursor.Sort.Field)
+	}
+	value, err := parseSortValue(cursor.Sort.Field, cursor.Value)
+	if err != nil {
+		return "", err
+	}
+
+	// Going forward through a descending listing means smaller values
+	comparison := ">"
+	if cursor.Sort.Desc != cursor.Backward {
+		comparison = "<"
+	}
+	return "(" + column + ", id) " + comparison + " (" + args.add(value) + ", " + args.add(cursor.ID) + ")", nil
+}
+
+// prefixPattern returns a LIKE pattern matching values that start with
+// prefix, ignoring case. LIKE wildcards in prefix match literally.
+func prefixPattern(prefix string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix))
+	return escaped + "%"
+}
@@ -4,12 +4,87 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/audit"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// pgUniqueViolation is the SQLSTATE for unique_violation
+const pgUniqueViolation = "23505"
+
+// uniqueConstraintFields maps unique indexes on the users table to the field they guard.
+// Both indexes skip deleted users, see migration 000004.
+var uniqueConstraintFields = map[string]string{
+	"users_live_username_key": "username",
+	"users_live_email_key":    "email",
+}
+
+// userColumn is a column read into a User, with the UserResponse fields
+// derived from it
+type userColumn struct {
+	expr   string
+	fields []string
+	always bool // selected whatever the field mask, e.g. version for the ETag
+	dest   func(*User) any
+}
+
+// userColumnList is every column read by scanUser, in scan order.
+// Queries selecting them must not alias the users table.
+var userColumnList = []userColumn{
+	{expr: "id", fields: []string{"id"}, always: true, dest: func(u *User) any { return &u.ID }},
+	{expr: "username", fields: []string{"username"}, dest: func(u *User) any { return &u.Username }},
+	{expr: "email", fields: []string{"email"}, dest: func(u *User) any { return &u.Email }},
+	{expr: "password_hash", dest: func(u *User) any { return &u.PasswordHash }},
+	{expr: "first_name", fields: []string{"first_name"}, dest: func(u *User) any { return &u.FirstName }},
+	{expr: "last_name", fields: []string{"last_name"}, dest: func(u *User) any { return &u.LastName }},
+	{expr: "status", fields: []string{"status", "is_active"}, dest: func(u *User) any { return &u.Status }},
+	{expr: "created_at", fields: []string{"created_at"}, dest: func(u *User) any { return &u.CreatedAt }},
+	{expr: "updated_at", fields: []string{"updated_at"}, dest: func(u *User) any { return &u.UpdatedAt }},
+	{expr: "deleted_at", fields: []string{"deleted_at"}, dest: func(u *User) any { return &u.DeletedAt }},
+	{expr: "last_login_at", fields: []string{"last_login_at"}, dest: func(u *User) any { return &u.LastLoginAt }},
+	{expr: "password_changed_at", fields: []string{"password_changed_at"}, dest: func(u *User) any { return &u.PasswordChangedAt }},
+	{expr: "email_verified_at", fields: []string{"email_verified_at"}, dest: func(u *User) any { return &u.EmailVerifiedAt }},
+	{expr: "version", always: true, dest: func(u *User) any { return &u.Version }},
+	{
+		expr:   "(SELECT confirmed_at FROM user_totp WHERE user_totp.user_id = users.id) AS mfa_enabled_at",
+		fields: []string{"mfa_enabled"},
+		dest:   func(u *User) any { return &u.MFAEnabledAt },
+	},
+}
+
+// userColumns is the column list read by scanUser, in scan order
+var userColumns = columnList(userColumnList)
+
+// userColumnsFor returns the columns needed to fill the fields of mask,
+// in scan order. An empty mask needs every column.
+func userColumnsFor(mask FieldMask) []userColumn {
+	if len(mask) == 0 {
+		return userColumnList
+	}
+
+	var columns []userColumn
+	for _, column := range userColumnList {
+		if column.always || slices.ContainsFunc(column.fields, mask.Has) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// columnList joins the select expressions of columns
+func columnList(columns []userColumn) string {
+	exprs := make([]string, len(columns))
+	for i, column := range columns {
+		exprs[i] = column.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
 // postgresRepository implements the Repository interface using PostgreSQL
 type postgresRepository struct {
 	db *pgxpool.Pool
@@ -22,148 +97,307 @@ func NewPostgresRepository(db *pgxpool.Pool) Repository {
 	}
 }
 
-// Create creates a new user in the database
+// Create creates a new user in the database and records it in the audit log
 func (r *postgresRepository) Create(ctx context.Context, user *User) error {
-	query := `
-		INSERT INTO users (username, email, password_hash, first_name, last_name, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
-	`
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO users (username, email, password_hash, first_name, last_name, status)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at, password_changed_at, version
+		`
+
+		err := tx.QueryRow(
+			ctx,
+			query,
+			user.Username,
+			user.Email,
+			user.PasswordHash,
+			user.FirstName,
+			user.LastName,
+			user.Status,
+		).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.PasswordChangedAt, &user.Version)
+
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", mapWriteError(err))
+		}
+
+		return recordUserChange(ctx, tx, audit.ActionCreate, nil, user)
+	})
+}
+
+// GetByID retrieves a user by their ID, excluding deleted users
+func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByIDFields retrieves a user by their ID, excluding deleted users,
+// reading only the columns needed for fields
+func (r *postgresRepository) GetByIDFields(ctx context.Context, id uuid.UUID, fields FieldMask) (*User, error) {
+	columns := userColumnsFor(fields)
+	query := `SELECT ` + columnList(columns) + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	return r.getColumns(ctx, columns, query, id)
+}
+
+// GetByIDIncludingDeleted retrieves a user by their ID in any status
+func (r *postgresRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByEmail retrieves a user by their email, excluding deleted users
+func (r *postgresRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
+	return r.getOne(ctx, query, email)
+}
+
+// GetByUsername retrieves a user by their username, excluding deleted users
+func (r *postgresRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1 AND deleted_at IS NULL`
+	return r.getOne(ctx, query, username)
+}
 
-	err := r.db.QueryRow(
-		ctx,
-		query,
-		user.Username,
-		user.Email,
-		user.PasswordHash,
-		user.FirstName,
-		user.LastName,
-		user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+// List retrieves users matching the filter in sort order with optional
+// pagination, reading only the columns needed for fields
+func (r *postgresRepository) List(ctx context.Context, filter ListFilter, sort Sort, fields FieldMask, limit, offset int) ([]*User, error) {
+	// Set default limit if not provided
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
 
+	var args queryArgs
+	order, err := orderSQL(sort, false)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return nil, err
 	}
+	columns := userColumnsFor(fields)
+	query := `SELECT ` + columnList(columns) + ` FROM users ` + whereSQL(filter, &args) + ` ` + order +
+		` LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
 
-	return nil
+	return r.queryUsers(ctx, columns, query, args...)
 }
 
-// GetByID retrieves a user by their ID
-func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-		       is_active, created_at, updated_at
-		FROM users
-		WHERE id = $1 AND is_active = true
-	`
+// ListAfter retrieves users matching the filter on the side of cursor it
+// points to, in the cursor's sort order either way
+func (r *postgresRepository) ListAfter(ctx context.Context, filter ListFilter, cursor Cursor, fields FieldMask, limit int) ([]*User, error) {
+	// Set default limit if not provided
+	if limit <= 0 {
+		limit = 10
+	}
 
-	user := &User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	// Backward pages are read in reverse starting next to the cursor, then flipped
+	var args queryArgs
+	seek, err := seekSQL(cursor, &args)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderSQL(cursor.Sort, cursor.Backward)
+	if err != nil {
+		return nil, err
+	}
+	columns := userColumnsFor(fields)
+	query := `SELECT ` + columnList(columns) + ` FROM users ` + whereSQL(filter, &args) + ` AND ` + seek + ` ` + order +
+		` LIMIT ` + args.add(limit)
 
+	users, err := r.queryUsers(ctx, columns, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
-		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, err
+	}
+	if cursor.Backward {
+		slices.Reverse(users)
 	}
 
-	return user, nil
+	return users, nil
 }
 
-// GetByEmail retrieves a user by their email
-func (r *postgresRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+// Update updates an existing, non-deleted user and records the changed fields.
+// The write only applies if the stored version still equals user.Version.
+func (r *postgresRepository) Update(ctx context.Context, user *User) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		before, err := lockUser(ctx, tx, user.ID, false)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE users
+			SET username = $1, email = $2, first_name = $3, last_name = $4,
+			    status = $5, email_verified_at = $6, updated_at = NOW(),
+			    version = version + 1
+			WHERE id = $7 AND version = $8
+			RETURNING updated_at, version
+		`
+
+		err = tx.QueryRow(
+			ctx,
+			query,
+			user.Username,
+			user.Email,
+			user.FirstName,
+			user.LastName,
+			user.Status,
+			user.EmailVerifiedAt,
+			user.ID,
+			user.Version,
+		).Scan(&user.UpdatedAt, &user.Version)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// The row exists (lockUser found it) but was changed since the caller read it
+				return ErrPreconditionFailed
+			}
+			return fmt.Errorf("failed to update user: %w", mapWriteError(err))
+		}
+
+		return recordUserChange(ctx, tx, audit.ActionUpdate, before, user)
+	})
+}
+
+// SetStatus moves a user from one status to user.Status.
+// deleted_at is set when entering the deleted status and cleared otherwise.
+func (r *postgresRepository) SetStatus(ctx context.Context, user *User, from UserStatus) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		before, err := lockUser(ctx, tx, user.ID, true)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE users
+			SET status = $1,
+			    deleted_at = CASE WHEN $1 = 'deleted' THEN NOW() ELSE NULL END,
+			    updated_at = NOW(), version = version + 1
+			WHERE id = $2 AND status = $3
+			RETURNING updated_at, deleted_at, version
+		`
+
+		err = tx.QueryRow(ctx, query, user.Status, user.ID, from).Scan(&user.UpdatedAt, &user.DeletedAt, &user.Version)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// The row exists (the caller loaded it) but its status changed concurrently
+				return ErrPreconditionFailed
+			}
+			return fmt.Errorf("failed to set user status: %w", mapWriteError(err))
+		}
+
+		return recordUserChange(ctx, tx, audit.ActionStatusChange, before, user)
+	})
+}
+
+// Delete soft deletes a user by setting status to deleted
+func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		before, err := lockUser(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE users
+			SET status = 'deleted', deleted_at = NOW(), updated_at = NOW(), version = version + 1
+			WHERE id = $1
+			RETURNING status, deleted_at, updated_at, version
+		`
+
+		after := *before
+		if err := tx.QueryRow(ctx, query, id).Scan(&after.Status, &after.DeletedAt, &after.UpdatedAt, &after.Version); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return recordUserChange(ctx, tx, audit.ActionDelete, before, &after)
+	})
+}
+
+// UpdatePassword stores user.PasswordHash and bumps password_changed_at
+func (r *postgresRepository) UpdatePassword(ctx context.Context, user *User) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return updatePassword(ctx, tx, user)
+	})
+}
+
+// updatePassword stores user.PasswordHash within tx and audits the change
+func updatePassword(ctx context.Context, tx pgx.Tx, user *User) error {
+	before, err := lockUser(ctx, tx, user.ID, false)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-		       is_active, created_at, updated_at
-		FROM users
-		WHERE email = $1 AND is_active = true
+		UPDATE users
+		SET password_hash = $1, password_changed_at = NOW(), updated_at = NOW(),
+		    version = version + 1
+		WHERE id = $2
+		RETURNING password_changed_at, updated_at, version
 	`
 
-	user := &User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err = tx.QueryRow(ctx, query, user.PasswordHash, user.ID).Scan(&user.PasswordChangedAt, &user.UpdatedAt, &user.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return recordUserChange(ctx, tx, audit.ActionPasswordChange, before, user)
+}
 
+// ReplacePasswordHash swaps the stored hash for an equivalent one (e.g. after
+// an algorithm upgrade) without touching password_changed_at. The hash is not
+// part of any response, so the version is left alone. It is not audited: the
+// password is unchanged, and a password_hash entry would read as a change the
+// user never made.
+func (r *postgresRepository) ReplacePasswordHash(ctx context.Context, id uuid.UUID, hash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(ctx, query, hash, id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
-		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return fmt.Errorf("failed to replace password hash: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
 	}
 
-	return user, nil
+	return nil
 }
 
-// GetByUsername retrieves a user by their username
-func (r *postgresRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+// RecordLogin stamps last_login_at for a successful authentication. It is not
+// audited: last_login_at is activity rather than account state, so it is
+// left out of auditFields and the diff would always be empty.
+func (r *postgresRepository) RecordLogin(ctx context.Context, user *User) error {
 	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-		       is_active, created_at, updated_at
-		FROM users
-		WHERE username = $1 AND is_active = true
+		UPDATE users
+		SET last_login_at = NOW(), version = version + 1
+		WHERE id = $1
+		RETURNING last_login_at, version
 	`
 
-	user := &User{}
-	err := r.db.QueryRow(ctx, query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.IsActive,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	err := r.db.QueryRow(ctx, query, user.ID).Scan(&user.LastLoginAt, &user.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return ErrUserNotFound
 		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return fmt.Errorf("failed to record login: %w", err)
 	}
 
-	return user, nil
+	return nil
 }
 
-// List retrieves all users with optional pagination
-func (r *postgresRepository) List(ctx context.Context, limit, offset int) ([]*User, error) {
-	// Set default limit if not provided
-	if limit <= 0 {
-		limit = 10
-	}
-	if offset < 0 {
-		offset = 0
+// Count returns the number of users matching the filter
+func (r *postgresRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	var args queryArgs
+	query := `SELECT COUNT(*) FROM users ` + whereSQL(filter, &args)
+
+	var count int64
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
-	query := `
-		SELECT id, username, email, password_hash, first_name, last_name, 
-		       is_active, created_at, updated_at
-		FROM users
-		WHERE is_active = true
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+	return count, nil
+}
 
-	rows, err := r.db.Query(ctx, query, limit, offset)
+// queryUsers runs a query selecting columns and returning many users
+func (r *postgresRepository) queryUsers(ctx context.Context, columns []userColumn, query string, args ...any) ([]*User, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -171,18 +405,7 @@ func (r *postgresRepository) List(ctx context.Context, limit, offset int) ([]*Us
 
 	var users []*User
 	for rows.Next() {
-		user := &User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Username,
-			&user.Email,
-			&user.PasswordHash,
-			&user.FirstName,
-			&user.LastName,
-			&user.IsActive,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		user, err := scanColumns(rows, columns)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -196,67 +419,66 @@ func (r *postgresRepository) List(ctx context.Context, limit, offset int) ([]*Us
 	return users, nil
 }
 
-// Update updates an existing user
-func (r *postgresRepository) Update(ctx context.Context, user *User) error {
-	query := `
-		UPDATE users
-		SET username = $1, email = $2, first_name = $3, last_name = $4, 
-		    is_active = $5, updated_at = NOW()
-		WHERE id = $6 AND is_active = true
-		RETURNING updated_at
-	`
-
-	err := r.db.QueryRow(
-		ctx,
-		query,
-		user.Username,
-		user.Email,
-		user.FirstName,
-		user.LastName,
-		user.IsActive,
-		user.ID,
-	).Scan(&user.UpdatedAt)
+// getOne runs a single-row userColumns query and maps no rows to ErrUserNotFound
+func (r *postgresRepository) getOne(ctx context.Context, query string, args ...any) (*User, error) {
+	return r.getColumns(ctx, userColumnList, query, args...)
+}
 
+// getColumns runs a single-row query selecting columns and maps no rows to
+// ErrUserNotFound
+func (r *postgresRepository) getColumns(ctx context.Context, columns []userColumn, query string, args ...any) (*User, error) {
+	user, err := scanColumns(r.db.QueryRow(ctx, query, args...), columns)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
-		return fmt.Errorf("failed to update user: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return nil
+	return user, nil
 }
 
-// Delete soft deletes a user by setting is_active to false
-func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `
-		UPDATE users
-		SET is_active = false, updated_at = NOW()
-		WHERE id = $1 AND is_active = true
-	`
+// scanUser scans a row selected with userColumns, followed by any extra
+// columns into extra
+func scanUser(row pgx.Row, extra ...any) (*User, error) {
+	return scanColumns(row, userColumnList, extra...)
+}
 
-	result, err := r.db.Exec(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+// scanColumns scans a row selected with columnList(columns), followed by any
+// extra columns into extra. Fields of columns not selected keep their zero value.
+func scanColumns(row pgx.Row, columns []userColumn, extra ...any) (*User, error) {
+	user := &User{}
+	dest := make([]any, 0, len(columns)+len(extra))
+	for _, column := range columns {
+		dest = append(dest, column.dest(user))
 	}
-
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
-
-	return nil
+	return user, nil
 }
 
-// Count returns the total number of active users
-func (r *postgresRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM users WHERE is_active = true`
-
-	var count int64
-	err := r.db.QueryRow(ctx, query).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count users: %w", err)
+// statusArgs returns the statuses to match, defaulting to active users
+func statusArgs(filter ListFilter) []string {
+	if len(filter.Statuses) == 0 {
+		return []string{string(StatusActive)}
+	}
+	statuses := make([]string, len(filter.Statuses))
+	for i, status := range filter.Statuses {
+		statuses[i] = string(status)
 	}
+	return statuses
+}
 
-	return count, nil
+// mapWriteError translates known PostgreSQL errors into domain errors
+func mapWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		field, ok := uniqueConstraintFields[pgErr.ConstraintName]
+		if !ok {
+			field = "user"
+		}
+		return &ConflictError{Field: field}
+	}
+	return err
 }
@@ -0,0 +1,111 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// searchFields are the columns a search highlights and matches by trigram
+// similarity, with their json field names. Each has a gin_trgm_ops index.
+var searchFields = []struct{ name, column string }{
+	{"username", "username::text"},
+	{"email", "email::text"},
+	{"first_name", "first_name"},
+	{"last_name", "last_name"},
+}
+
+// Search retrieves users matching text, full-text matches first.
+// The full-text query matches every word of text as a word prefix; users
+// whose fields are only similar to text (typos) follow, ranked by the
+// word similarity of their closest field.
+func (r *postgresRepository) Search(ctx context.Context, filter ListFilter, text string, limit, offset int) ([]*UserSearchHit, error) {
+	// Set default limit if not provided
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var args queryArgs
+	where := whereSQL(filter, &args)
+	tsquery := "to_tsquery('simple', " + args.add(tsQuery(searchTerms(text))) + ")"
+	similar := args.add(strings.TrimSpace(text))
+
+	var matches, scores, headlines []string
+	matches = append(matches, "search_vector @@ "+tsquery)
+	scores = append(scores, "ts_rank(search_vector, "+tsquery+")")
+	for _, field := range searchFields {
+		matches = append(matches, field.column+" %> "+similar)
+		scores = append(scores, "word_similarity("+similar+", coalesce("+field.column+", ''))")
+		headlines = append(headlines, headlineSQL(field.column, tsquery))
+	}
+
+	query := `
+		SELECT ` + userColumns + `,
+			search_vector @@ ` + tsquery + ` AS exact_match,
+			greatest(` + strings.Join(scores, ", ") + `)::float8 AS search_rank,
+			` + strings.Join(headlines, ",\n\t\t\t") + `
+		FROM users
+		` + where + ` AND (` + strings.Join(matches, " OR ") + `)
+		ORDER BY exact_match DESC, search_rank DESC, id
+		LIMIT ` + args.add(limit) + ` OFFSET ` + args.add(offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []*UserSearchHit{}
+	for rows.Next() {
+		hit := &UserSearchHit{}
+		highlighted := make([]*string, len(searchFields))
+		extra := []any{&hit.Exact, &hit.Rank}
+		for i := range highlighted {
+			extra = append(extra, &highlighted[i])
+		}
+
+		hit.User, err = scanUser(rows, extra...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		// ts_headline returns the whole value, marked up or not
+		for i, value := range highlighted {
+			if value == nil || !strings.Contains(*value, "<mark>") {
+				continue
+			}
+			if hit.Highlights == nil {
+				hit.Highlights = map[string]string{}
+			}
+			hit.Highlights[searchFields[i].name] = *value
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return hits, nil
+}
+
+// tsQuery joins terms into a to_tsquery expression requiring each of them
+// as a word prefix. searchTerms only returns letters and digits, so terms
+// cannot contain tsquery operators.
+func tsQuery(terms []string) string {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	return strings.Join(prefixes, " & ")
+}
+
+// headlineSQL wraps the words of column matching tsquery in <mark> tags.
+// The value is HTML-escaped first, so the tags are its only markup.
+func headlineSQL(column, tsquery string) string {
+	escaped := "replace(replace(replace(" + column + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+	return "ts_headline('simple', " + escaped + ", " + tsquery + ", 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>')"
+}
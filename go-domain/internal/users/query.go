@@ -0,0 +1,104 @@
+package users
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Fields user listings can be sorted by. Each is NOT NULL, so the sort value
+// and the id always form a usable cursor.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortUsername  = "username"
+	SortEmail     = "email"
+)
+
+// SortableFields lists the accepted sort fields in documentation order
+var SortableFields = []string{SortCreatedAt, SortUpdatedAt, SortUsername, SortEmail}
+
+// Sort orders a user listing by one field; ties are broken by id in the
+// same direction
+type Sort struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d,omitempty"`
+}
+
+// DefaultSort lists the newest users first
+var DefaultSort = Sort{Field: SortCreatedAt, Desc: true}
+
+// ParseSort parses "field" (ascending) or "-field" (descending).
+// Returns false for fields not in SortableFields.
+func ParseSort(raw string) (Sort, bool) {
+	sort := Sort{Field: raw}
+	if field, ok := strings.CutPrefix(raw, "-"); ok {
+		sort = Sort{Field: field, Desc: true}
+	}
+	if !slices.Contains(SortableFields, sort.Field) {
+		return Sort{}, false
+	}
+	return sort, true
+}
+
+// String formats the sort the way ParseSort reads it
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// UserQuery is a validated request for a page of users
+type UserQuery struct {
+	Filter ListFilter
+	Sort   Sort
+	Page   PageRequest
+	Fields FieldMask // response fields to read, all when empty
+}
+
+// sortValue returns the value user has for the sort field, as stored in cursors
+func sortValue(user *User, field string) string {
+	switch field {
+	case SortUpdatedAt:
+		return user.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case SortUsername:
+		return user.Username
+	case SortEmail:
+		return user.Email
+	default:
+		return user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// parseSortValue converts a cursor value back to the column's Go type
+func parseSortValue(field, value string) (any, error) {
+	switch field {
+	case SortCreatedAt, SortUpdatedAt:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", field, err)
+		}
+		return t, nil
+	case SortUsername, SortEmail:
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsortable field %q", field)
+}
+
+// maxSearchTerms caps the words of a search that reach the full-text query
+const maxSearchTerms = 8
+
+// searchTerms splits a search into lower-cased words of letters and digits.
+// Punctuation separates words, so "john.doe@" yields john and doe.
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	return words
+}
@@ -2,33 +2,161 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Repository defines the interface for user data operations
+// Repository defines the interface for user data operations.
+// Create, Update, SetStatus, Delete and UpdatePassword write an audit event
+// in the same transaction as the change.
 type Repository interface {
-	// Create creates a new user in the database
+	// Create creates a new user in the database.
+	// Returns a *ConflictError if a non-deleted user has the same username or email.
 	Create(ctx context.Context, user *User) error
 
-	// GetByID retrieves a user by their ID
+	// GetByID retrieves a user by their ID (deleted users are not returned)
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 
+	// GetByIDFields retrieves a user by their ID (deleted users are not returned),
+	// reading only what the fields of the mask need; an empty mask reads everything
+	GetByIDFields(ctx context.Context, id uuid.UUID, fields FieldMask) (*User, error)
+
+	// GetByIDIncludingDeleted retrieves a user by their ID in any status
+	GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*User, error)
+
 	// GetByEmail retrieves a user by their email
 	GetByEmail(ctx context.Context, email string) (*User, error)
 
 	// GetByUsername retrieves a user by their username
 	GetByUsername(ctx context.Context, username string) (*User, error)
 
-	// List retrieves all users with optional pagination
-	List(ctx context.Context, limit, offset int) ([]*User, error)
+	// List retrieves users matching the filter with optional pagination,
+	// ordered by sort with id as tiebreaker. Only what the fields of the mask
+	// need is read, plus the id and version; an empty mask reads everything.
+	List(ctx context.Context, filter ListFilter, sort Sort, fields FieldMask, limit, offset int) ([]*User, error)
+
+	// ListAfter retrieves up to limit users matching the filter on the side of
+	// cursor it points to, ordered like List with the cursor's sort
+	ListAfter(ctx context.Context, filter ListFilter, cursor Cursor, fields FieldMask, limit int) ([]*User, error)
 
-	// Update updates an existing user
+	// Search retrieves users whose username, email or names match text, as
+	// word prefixes or by trigram similarity, most relevant first
+	Search(ctx context.Context, filter ListFilter, text string, limit, offset int) ([]*UserSearchHit, error)
+
+	// Update updates an existing user and increments user.Version.
+	// Returns ErrPreconditionFailed if the stored version is not user.Version.
 	Update(ctx context.Context, user *User) error
 
-	// Delete deletes a user by their ID (soft delete by setting is_active to false)
+	// SetStatus changes a user's status to user.Status if it is currently from.
+	// Returns ErrPreconditionFailed if the status no longer matches.
+	SetStatus(ctx context.Context, user *User, from UserStatus) error
+
+	// Delete deletes a user by their ID (soft delete by setting status to deleted)
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// Count returns the total number of users
-	Count(ctx context.Context) (int64, error)
+	// UpdatePassword stores user.PasswordHash and bumps password_changed_at
+	UpdatePassword(ctx context.Context, user *User) error
+
+	// ReplacePasswordHash swaps the stored hash for an equivalent one without
+	// bumping password_changed_at (used for transparent rehashing)
+	ReplacePasswordHash(ctx context.Context, id uuid.UUID, hash string) error
+
+	// RecordLogin sets last_login_at to now and stores it on user
+	RecordLogin(ctx context.Context, user *User) error
+
+	// Count returns the number of users matching the filter
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+
+	// CreatePasswordResetToken stores the hash of a newly issued reset token
+	CreatePasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash []byte, expiresAt time.Time) error
+
+	// CountPasswordResetTokensSince counts tokens issued to a user after since
+	CountPasswordResetTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+
+	// GetPasswordResetTokenUser returns the user of a valid token without consuming it.
+	// Returns ErrInvalidToken if the token is unknown, used or expired.
+	GetPasswordResetTokenUser(ctx context.Context, tokenHash []byte) (uuid.UUID, error)
+
+	// ResetPassword consumes a valid token of user, stores user.PasswordHash and
+	// invalidates the user's other tokens in one transaction.
+	// Returns ErrInvalidToken if the token is unknown, used, expired or not the user's.
+	ResetPassword(ctx context.Context, tokenHash []byte, user *User) error
+
+	// CreateEmailVerificationToken stores the hash of a token sent to email
+	CreateEmailVerificationToken(ctx context.Context, userID uuid.UUID, email string, tokenHash []byte, expiresAt time.Time) error
+
+	// CountEmailVerificationTokensSince counts verification tokens issued to a user after since
+	CountEmailVerificationTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+
+	// ConsumeEmailVerificationToken marks a valid token as used and returns its user and email.
+	// Returns ErrInvalidToken if the token is unknown, used or expired.
+	ConsumeEmailVerificationToken(ctx context.Context, tokenHash []byte) (uuid.UUID, string, error)
+
+	// InvalidateEmailVerificationTokens marks all of a user's outstanding tokens as used
+	InvalidateEmailVerificationTokens(ctx context.Context, userID uuid.UUID) error
+
+	// MarkEmailVerified stamps email_verified_at if the user's email is still email
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (*User, error)
+
+	// GetLoginThrottle returns the failed-login state of a user or client IP.
+	// Subjects without recent failures get an empty throttle.
+	GetLoginThrottle(ctx context.Context, subjectType, subject string) (*LoginThrottle, error)
+
+	// RecordLoginFailure counts a failed login, restarting the count when the
+	// previous failure is older than window, and returns the updated state
+	RecordLoginFailure(ctx context.Context, subjectType, subject string, window time.Duration) (*LoginThrottle, error)
+
+	// LockLoginSubject locks a subject until event.LockedUntil and records the event
+	LockLoginSubject(ctx context.Context, event LockoutEvent) error
+
+	// ClearLoginThrottle resets a subject's failures and lockout, recording event if set
+	ClearLoginThrottle(ctx context.Context, subjectType, subject string, event *LockoutEvent) error
+
+	// GetTOTP returns a user's TOTP credential.
+	// Returns ErrMFANotEnrolled if the user has none.
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*TOTPCredential, error)
+
+	// SavePendingTOTP stores an unconfirmed encrypted secret.
+	// Returns ErrMFAAlreadyEnabled if the user's TOTP is already confirmed.
+	SavePendingTOTP(ctx context.Context, userID uuid.UUID, ciphertext []byte) error
+
+	// ConfirmTOTP confirms the pending secret, marks step used and stores recovery code hashes
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes [][]byte) error
+
+	// UseTOTPStep records an accepted TOTP step.
+	// Returns ErrInvalidMFACode if the step is not newer than the last one used.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+
+	// CreateMFAChallenge stores the hash of a challenge issued after a correct password
+	CreateMFAChallenge(ctx context.Context, userID uuid.UUID, challengeHash []byte, expiresAt time.Time) error
+
+	// GetMFAChallengeUser returns the user of a valid challenge without consuming it.
+	// Returns ErrInvalidToken if the challenge is unknown, used or expired.
+	GetMFAChallengeUser(ctx context.Context, challengeHash []byte) (uuid.UUID, error)
+
+	// ConsumeMFAChallenge marks a valid challenge as used and returns its user.
+	// Returns ErrInvalidToken if the challenge is unknown, used or expired.
+	ConsumeMFAChallenge(ctx context.Context, challengeHash []byte) (uuid.UUID, error)
+
+	// ReplaceRecoveryCodes replaces all of a user's recovery code hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes [][]byte) error
+
+	// ConsumeRecoveryCode marks a recovery code used and returns how many are left.
+	// Returns ErrInvalidMFACode if the code is unknown or used.
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) (int, error)
+
+	// ListHistory retrieves a user's versions, newest first, with optional pagination
+	ListHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*UserVersion, error)
+
+	// CountHistory returns the number of versions recorded for a user
+	CountHistory(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// GetRevision retrieves the version of a user with the given revision number.
+	// Returns ErrRevisionNotFound if the user has no such revision.
+	GetRevision(ctx context.Context, userID uuid.UUID, revision int) (*UserVersion, error)
+
+	// GetVersionAt retrieves the version of a user that was current at time at.
+	// Returns ErrRevisionNotFound if the user did not exist yet.
+	GetVersionAt(ctx context.Context, userID uuid.UUID, at time.Time) (*UserVersion, error)
 }
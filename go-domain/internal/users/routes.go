@@ -1,28 +1,71 @@
 package users
 
 import (
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// RegisterRoutes registers all user-related routes
-func RegisterRoutes(router *gin.RouterGroup, db *pgxpool.Pool) {
+// RegisterRoutes registers all user-related routes.
+// authz guards the routes that need a permission beyond being signed in.
+// idempotent makes retries safe on user creation; it is kept off routes whose
+// responses hold secrets or whose replay would skip a check, such as
+// authentication, MFA, passwords and tokens.
+func RegisterRoutes(router *gin.RouterGroup, db *pgxpool.Pool, cfg Config, authz roles.Authorizer, idempotent gin.HandlerFunc) {
+	// Register custom validation rules for request binding
+	registerValidators()
+
 	// Create repository with database connection
 	repo := NewPostgresRepository(db)
 	
 	// Create service with repository
-	service := NewService(repo)
+	service := NewService(repo, NewLogPublisher(), NewPasswordHasher(cfg.Argon2), NewPasswordPolicy(cfg.PasswordPolicy), cfg.Lockout, cfg.TOTP, authz)
 	
 	// Create handler with service
 	handler := NewHandler(service)
 
+	// Permission gates
+	canRead := roles.RequirePermission(authz, roles.PermUsersRead)
+	canAdmin := roles.RequirePermission(authz, roles.PermUsersAdmin)
+
 	// Register routes
 	users := router.Group("/users")
 	{
-		users.GET("", handler.ListUsers)        // GET /api/v1/users
-		users.GET("/:id", handler.GetUser)      // GET /api/v1/users/:id
-		users.POST("", handler.CreateUser)      // POST /api/v1/users
-		users.PATCH("/:id", handler.UpdateUser) // PATCH /api/v1/users/:id
-		users.DELETE("/:id", handler.DeleteUser) // DELETE /api/v1/users/:id
+		users.GET("", canRead, handler.ListUsers) // GET /api/v1/users
+		users.GET("/search", canRead, handler.SearchUsers) // GET /api/v1/users/search?q=
+		users.GET("/:id", handler.GetUser)      // GET /api/v1/users/:id (self or users:read, ?as_of=<timestamp> or ?fields=)
+		users.POST("", idempotent, handler.CreateUser) // POST /api/v1/users (Idempotency-Key)
+		users.PATCH("/:id", handler.UpdateUser) // PATCH /api/v1/users/:id (self or admin, ?update_mask=)
+		users.DELETE("/:id", handler.DeleteUser) // DELETE /api/v1/users/:id (self or admin)
+
+		// Authentication (called by the BFF login flow)
+		users.POST("/authenticate", handler.Authenticate) // POST /api/v1/users/authenticate
+
+		// Passwords
+		users.PUT("/:id/password", handler.ChangePassword)        // PUT /api/v1/users/:id/password (self)
+		users.POST("/:id/password/reset", canAdmin, handler.ResetPassword) // POST /api/v1/users/:id/password/reset (admin)
+
+		// Forgotten password flow
+		users.POST("/password-reset", handler.RequestPasswordReset)         // POST /api/v1/users/password-reset
+		users.POST("/password-reset/confirm", handler.ConfirmPasswordReset) // POST /api/v1/users/password-reset/confirm
+
+		// Email verification
+		users.POST("/:id/verify-email", handler.ResendEmailVerification)      // POST /api/v1/users/:id/verify-email (self or admin)
+		users.POST("/verify-email/confirm", handler.ConfirmEmailVerification) // POST /api/v1/users/verify-email/confirm
+
+		// Multi-factor authentication
+		users.POST("/:id/mfa/totp", handler.EnrollTOTP)                          // POST /api/v1/users/:id/mfa/totp (self)
+		users.POST("/:id/mfa/totp/confirm", handler.ConfirmTOTP)                 // POST /api/v1/users/:id/mfa/totp/confirm (self)
+		users.POST("/mfa/verify", handler.VerifyMFA)                             // POST /api/v1/users/mfa/verify (challenge from authenticate)
+		users.POST("/:id/mfa/recovery-codes", handler.RegenerateRecoveryCodes) // POST /api/v1/users/:id/mfa/recovery-codes (self)
+
+		// Version history
+		users.GET("/:id/history", handler.ListUserHistory)                       // GET /api/v1/users/:id/history (self or users:read)
+		users.POST("/:id/history/:revision/revert", canAdmin, handler.RevertUser) // POST /api/v1/users/:id/history/:revision/revert (admin)
+
+		// Lifecycle
+		users.POST("/:id/suspend", canAdmin, handler.SuspendUser) // POST /api/v1/users/:id/suspend (admin)
+		users.POST("/:id/restore", canAdmin, handler.RestoreUser) // POST /api/v1/users/:id/restore (admin)
+		users.POST("/:id/unlock", canAdmin, handler.UnlockUser)   // POST /api/v1/users/:id/unlock (admin)
 	}
 }
@@ -0,0 +1,28 @@
+package users
+
+import (
+	"context"
+	"fmt"
+)
+
+// SearchUsers finds users by part of their username, email or names.
+// Full-text matches on word prefixes come first; users only similar to the
+// text, such as a misspelled email, follow so typos still find someone.
+func (s *svc) SearchUsers(ctx context.Context, query SearchQuery) ([]*UserSearchResult, error) {
+	if len(searchTerms(query.Text)) == 0 {
+		return nil, &ValidationError{Field: "q", Rule: "required", Message: "must contain a letter or digit"}
+	}
+
+	hits, err := s.repo.Search(ctx, query.Filter, query.Text, pageLimit(query.Limit), max(query.Offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	results := make([]*UserSearchResult, len(hits))
+	for i, hit := range hits {
+		result := hit.ToResponse()
+		results[i] = &result
+	}
+
+	return results, nil
+}
@@ -2,51 +2,131 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/roles"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Service interface {
 	// User CRUD operations
 	CreateUser(ctx context.Context, req CreateUserRequest) (*UserResponse, error)
-	GetUserByID(ctx context.Context, id uuid.UUID) (*UserResponse, error)
+	GetUserByID(ctx context.Context, id uuid.UUID, fields FieldMask) (*UserResponse, error)
 	GetUserByEmail(ctx context.Context, email string) (*UserResponse, error)
 	GetUserByUsername(ctx context.Context, username string) (*UserResponse, error)
-	ListUsers(ctx context.Context, limit, offset int) ([]*UserResponse, error)
-	UpdateUser(ctx context.Context, id uuid.UUID, req UpdateUserRequest) (*UserResponse, error)
+	ListUsers(ctx context.Context, query UserQuery) (*UserPage, error)
+	SearchUsers(ctx context.Context, query SearchQuery) ([]*UserSearchResult, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, req UpdateUserRequest, ifMatch []int) (*UserResponse, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
-	GetUsersCount(ctx context.Context) (int64, error)
+
+	// Authentication
+	Authenticate(ctx context.Context, req AuthenticateRequest, clientIP string) (*AuthenticateResponse, error)
+	UnlockUser(ctx context.Context, id uuid.UUID) error
+
+	// Passwords
+	ChangePassword(ctx context.Context, id uuid.UUID, req ChangePasswordRequest, clientIP string) (*UserResponse, error)
+	ResetPassword(ctx context.Context, id uuid.UUID, req ResetPasswordRequest) (*UserResponse, error)
+	RequestPasswordReset(ctx context.Context, req PasswordResetRequest) error
+	ConfirmPasswordReset(ctx context.Context, req ConfirmPasswordResetRequest) error
+
+	// Email verification
+	ResendEmailVerification(ctx context.Context, id uuid.UUID) error
+	ConfirmEmailVerification(ctx context.Context, req ConfirmEmailVerificationRequest) (*UserResponse, error)
+
+	// Multi-factor authentication
+	EnrollTOTP(ctx context.Context, id uuid.UUID) (*TOTPEnrollmentResponse, error)
+	ConfirmTOTP(ctx context.Context, id uuid.UUID, req ConfirmTOTPRequest) (*RecoveryCodesResponse, error)
+	VerifyMFA(ctx context.Context, req VerifyMFARequest, clientIP string) (*MFAVerificationResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, id uuid.UUID) (*RecoveryCodesResponse, error)
+
+	// Version history
+	ListUserHistory(ctx context.Context, id uuid.UUID, limit, offset int) ([]*UserVersionResponse, error)
+	CountUserHistory(ctx context.Context, id uuid.UUID) (int64, error)
+	GetUserAsOf(ctx context.Context, id uuid.UUID, at time.Time) (*UserVersionResponse, error)
+	RevertUser(ctx context.Context, id uuid.UUID, revision int) (*UserResponse, error)
+
+	// Lifecycle operations
+	SuspendUser(ctx context.Context, id uuid.UUID) (*UserResponse, error)
+	RestoreUser(ctx context.Context, id uuid.UUID) (*UserResponse, error)
 }
 
 type svc struct {
-	repo Repository
+	repo    Repository
+	events  EventPublisher
+	hasher  PasswordHasher
+	policy  *PasswordPolicy
+	lockout LockoutConfig
+	totp    TOTPConfig
+	secrets *secretBox // nil when no TOTP encryption key is configured
+	authz   roles.Authorizer
+
+	// dummyHash is verified against when a login matches no user, so
+	// unknown users take as long to reject as wrong passwords
+	dummyHash func() string
 }
 
 // NewService creates a new user service
-func NewService(repo Repository) Service {
+func NewService(repo Repository, events EventPublisher, hasher PasswordHasher, policy *PasswordPolicy, lockout LockoutConfig, totp TOTPConfig, authz roles.Authorizer) Service {
+	secrets, err := newSecretBox(totp.EncryptionKey)
+	if err != nil {
+		slog.Error("TOTP disabled, invalid encryption key", "error", err)
+	}
+
 	return &svc{
-		repo: repo,
+		repo:    repo,
+		events:  events,
+		hasher:  hasher,
+		policy:  policy,
+		lockout: lockout,
+		totp:    totp,
+		secrets: secrets,
+		authz:   authz,
+		dummyHash: sync.OnceValue(func() string {
+			hash, err := hasher.Hash("dummy-password-for-timing")
+			if err != nil {
+				panic(fmt.Sprintf("failed to create dummy password hash: %v", err))
+			}
+			return hash
+		}),
 	}
 }
 
-// CreateUser creates a new user with hashed password
+// CreateUser creates a new user with hashed password.
+//
+// Usernames and emails only need to be unique among active users. Signing up
+// with the email or username of a deleted account creates a new, unrelated
+// account; the deleted row keeps its data and is never merged or reactivated.
 func (s *svc) CreateUser(ctx context.Context, req CreateUserRequest) (*UserResponse, error) {
+	// Check password strength against the normalized identity
+	if err := s.policy.Check("password", req.Password, normalizeUsername(req.Username), normalizeEmail(req.Email)); err != nil {
+		return nil, err
+	}
+
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
 	// Create user model
 	user := &User{
-		Username:     req.Username,
-		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		IsActive:     true,
+		Username:     normalizeUsername(req.Username),
+		Email:        normalizeEmail(req.Email),
+		PasswordHash: hashedPassword,
+		FirstName:    normalizeName(req.FirstName),
+		LastName:     normalizeName(req.LastName),
+		Status:       StatusActive,
+	}
+	if user.Username == "" {
+		return nil, &ValidationError{Field: "username", Rule: "required", Message: "must not be empty"}
+	}
+	if user.Email == "" {
+		return nil, &ValidationError{Field: "email", Rule: "required", Message: "must not be empty"}
 	}
 
 	// Save to database
@@ -54,13 +134,24 @@ func (s *svc) CreateUser(ctx context.Context, req CreateUserRequest) (*UserRespo
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// The account exists either way; the user can ask for a new link later
+	if err := s.issueEmailVerification(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Failed to issue email verification", "error", err, "user_id", user.ID)
+	}
+
 	response := user.ToResponse()
 	return &response, nil
 }
 
-// GetUserByID retrieves a user by their ID
-func (s *svc) GetUserByID(ctx context.Context, id uuid.UUID) (*UserResponse, error) {
-	user, err := s.repo.GetByID(ctx, id)
+// GetUserByID retrieves a user by their ID. Only the response fields in
+// fields are filled; an empty mask fills them all. Callers may read their
+// own account; anyone else's requires users:read.
+func (s *svc) GetUserByID(ctx context.Context, id uuid.UUID, fields FieldMask) (*UserResponse, error) {
+	if err := s.authorizeRead(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByIDFields(ctx, id, fields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -71,7 +162,7 @@ func (s *svc) GetUserByID(ctx context.Context, id uuid.UUID) (*UserResponse, err
 
 // GetUserByEmail retrieves a user by their email
 func (s *svc) GetUserByEmail(ctx context.Context, email string) (*UserResponse, error) {
-	user, err := s.repo.GetByEmail(ctx, email)
+	user, err := s.repo.GetByEmail(ctx, normalizeEmail(email))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -82,7 +173,7 @@ func (s *svc) GetUserByEmail(ctx context.Context, email string) (*UserResponse,
 
 // GetUserByUsername retrieves a user by their username
 func (s *svc) GetUserByUsername(ctx context.Context, username string) (*UserResponse, error) {
-	user, err := s.repo.GetByUsername(ctx, username)
+	user, err := s.repo.GetByUsername(ctx, normalizeUsername(username))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -91,69 +182,227 @@ func (s *svc) GetUserByUsername(ctx context.Context, username string) (*UserResp
 	return &response, nil
 }
 
-// ListUsers retrieves all users with pagination
-func (s *svc) ListUsers(ctx context.Context, limit, offset int) ([]*UserResponse, error) {
-	users, err := s.repo.List(ctx, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
+// ListUsers retrieves a page of users matching the query's filter in its
+// sort order, together with the number of users matching the filter.
+//
+// Cursor pages are stable while users sign up or are deleted, and cost the
+// same at any depth. Offset pages remain for clients that jump to a page
+// number; both kinds return cursors to the neighbouring pages.
+func (s *svc) ListUsers(ctx context.Context, query UserQuery) (*UserPage, error) {
+	filter, page := query.Filter, query.Page
+	limit := pageLimit(page.Limit)
+
+	// One extra row tells whether another page follows in the read direction.
+	// A cursor comes from a neighbouring page, so the side it came from is
+	// assumed not to be empty.
+	// Cursors hold the sort value, so it is read even when not requested.
+	// Sortable fields are named like the response fields they fill.
+	fields := query.Fields
+	if len(fields) > 0 {
+		fields = append(slices.Clip(fields), query.Sort.Field)
 	}
 
-	responses := make([]*UserResponse, len(users))
+	var users []*User
+	var hasPrev, hasNext bool
+	var err error
+	if page.Cursor != "" {
+		var cursor Cursor
+		cursor, err = decodeCursor(page.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+
+		users, err = s.repo.ListAfter(ctx, filter, cursor, fields, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		more := len(users) > limit
+		if cursor.Backward {
+			// Backward pages come back in listing order too, so the extra row is at the front
+			if more {
+				users = users[1:]
+			}
+			hasPrev, hasNext = more, true
+		} else {
+			if more {
+				users = users[:limit]
+			}
+			hasPrev, hasNext = true, more
+		}
+	} else {
+		users, err = s.repo.List(ctx, filter, query.Sort, fields, limit+1, page.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		hasPrev, hasNext = page.Offset > 0, len(users) > limit
+		if hasNext {
+			users = users[:limit]
+		}
+	}
+
+	result := &UserPage{Users: make([]*UserResponse, len(users))}
 	for i, user := range users {
 		response := user.ToResponse()
-		responses[i] = &response
+		result.Users[i] = &response
+	}
+	if len(users) > 0 {
+		if hasNext {
+			result.NextCursor = cursorAt(users[len(users)-1], query.Sort, false)
+		}
+		if hasPrev {
+			result.PrevCursor = cursorAt(users[0], query.Sort, true)
+		}
 	}
 
-	return responses, nil
+	result.Total, err = s.repo.Count(ctx, filter)
+	if err != nil {
+		// The page is still useful without a total
+		slog.ErrorContext(ctx, "Failed to count users", "error", err)
+	}
+
+	return result, nil
 }
 
-// UpdateUser updates an existing user
-func (s *svc) UpdateUser(ctx context.Context, id uuid.UUID, req UpdateUserRequest) (*UserResponse, error) {
-	// Get existing user
-	user, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+// maxUpdateAttempts bounds how often an unconditional update is retried when
+// another write lands between reading and saving the user
+const maxUpdateAttempts = 3
+
+// UpdateUser updates an existing user.
+//
+// ifMatch lists the versions the caller expects the user to be at (from the
+// If-Match header); the update fails with ErrPreconditionFailed if the user is
+// at another version. With a nil ifMatch the update applies to the latest
+// version, re-reading the user if it changed concurrently, so fields the
+// request leaves alone are never overwritten with stale values.
+func (s *svc) UpdateUser(ctx context.Context, id uuid.UUID, req UpdateUserRequest, ifMatch []int) (*UserResponse, error) {
+	// Callers edit their own profile; status changes are for admins
+	var privileged []string
+	if req.IsActive != nil {
+		privileged = append(privileged, "is_active")
 	}
+	if err := s.authorizeChange(ctx, id, privileged...); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Get existing user
+		user, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if ifMatch != nil && !slices.Contains(ifMatch, user.Version) {
+			return nil, ErrPreconditionFailed
+		}
 
-	// Update fields if provided
+		emailChanged, err := applyUpdate(user, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// Save changes; the repository rejects the write if the version moved on
+		err = s.repo.Update(ctx, user)
+		if errors.Is(err, ErrPreconditionFailed) && ifMatch == nil && attempt < maxUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+
+		if emailChanged {
+			if err := s.issueEmailVerification(ctx, user); err != nil {
+				slog.ErrorContext(ctx, "Failed to issue email verification", "error", err, "user_id", user.ID)
+			}
+		}
+
+		response := user.ToResponse()
+		return &response, nil
+	}
+}
+
+// applyUpdate copies the fields set in req onto user and reports whether the
+// email address changed
+func applyUpdate(user *User, req UpdateUserRequest) (bool, error) {
+	emailChanged := false
 	if req.Username != nil {
-		user.Username = *req.Username
+		username := normalizeUsername(*req.Username)
+		if username == "" {
+			return false, &ValidationError{Field: "username", Rule: "required", Message: "must not be empty"}
+		}
+		user.Username = username
 	}
 	if req.Email != nil {
-		user.Email = *req.Email
+		email := normalizeEmail(*req.Email)
+		if email == "" {
+			return false, &ValidationError{Field: "email", Rule: "required", Message: "must not be empty"}
+		}
+		if email != user.Email {
+			// A new address must be verified again
+			user.EmailVerifiedAt = nil
+			emailChanged = true
+		}
+		user.Email = email
 	}
 	if req.FirstName != nil {
-		user.FirstName = req.FirstName
+		user.FirstName = normalizeName(req.FirstName)
 	}
 	if req.LastName != nil {
-		user.LastName = req.LastName
+		user.LastName = normalizeName(req.LastName)
 	}
 	if req.IsActive != nil {
-		user.IsActive = *req.IsActive
+		// is_active only toggles between active and suspended; deletion has its own endpoint
+		if *req.IsActive {
+			user.Status = StatusActive
+		} else {
+			user.Status = StatusSuspended
+		}
 	}
-
-	// Save changes
-	if err := s.repo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
-	}
-
-	response := user.ToResponse()
-	return &response, nil
+	return emailChanged, nil
 }
 
-// DeleteUser soft deletes a user
+// DeleteUser soft deletes a user. Callers may delete their own account;
+// deleting anyone else requires users:admin.
 func (s *svc) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	if err := s.authorizeChange(ctx, id); err != nil {
+		return err
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 	return nil
 }
 
-// GetUsersCount returns the total number of active users
-func (s *svc) GetUsersCount(ctx context.Context) (int64, error) {
-	count, err := s.repo.Count(ctx)
+// SuspendUser blocks an active user without releasing their username or email
+func (s *svc) SuspendUser(ctx context.Context, id uuid.UUID) (*UserResponse, error) {
+	return s.transition(ctx, id, StatusSuspended, StatusActive)
+}
+
+// RestoreUser reactivates a suspended or deleted user.
+// Restoring a deleted user fails with a *ConflictError if their username
+// or email has since been taken by another account.
+func (s *svc) RestoreUser(ctx context.Context, id uuid.UUID) (*UserResponse, error) {
+	return s.transition(ctx, id, StatusActive, StatusSuspended, StatusDeleted)
+}
+
+// transition moves a user to status `to` if their current status is one of `from`
+func (s *svc) transition(ctx context.Context, id uuid.UUID, to UserStatus, from ...UserStatus) (*UserResponse, error) {
+	user, err := s.repo.GetByIDIncludingDeleted(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count users: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-	return count, nil
+
+	if !slices.Contains(from, user.Status) {
+		return nil, fmt.Errorf("cannot move user from %s to %s: %w", user.Status, to, ErrInvalidStatusTransition)
+	}
+
+	current := user.Status
+	user.Status = to
+	if err := s.repo.SetStatus(ctx, user, current); err != nil {
+		return nil, fmt.Errorf("failed to set user status: %w", err)
+	}
+
+	response := user.ToResponse()
+	return &response, nil
 }
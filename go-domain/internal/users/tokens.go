@@ -0,0 +1,28 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// tokenBytes is the amount of randomness in emailed tokens
+const tokenBytes = 32
+
+// newToken returns a URL-safe random token and the hash to store for it
+func newToken() (string, []byte, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken hashes a token for lookup. Tokens are high-entropy random
+// values, so a fast hash is enough (unlike passwords).
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
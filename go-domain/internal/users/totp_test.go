@@ -0,0 +1,66 @@
+package users
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 appendix B test vectors
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := totpCode(rfc6238Secret, totpStep(time.Unix(tt.unix, 0))); got != tt.want {
+				t.Errorf("totpCode() at %d = %s, want %s", tt.unix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOTPMatch(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: "050471", skew: 1, wantStep: step, wantOK: true},
+		{name: "previous step within skew", code: totpCode(rfc6238Secret, step-1), skew: 1, wantStep: step - 1, wantOK: true},
+		{name: "next step within skew", code: totpCode(rfc6238Secret, step+1), skew: 1, wantStep: step + 1, wantOK: true},
+		{name: "previous step without skew", code: totpCode(rfc6238Secret, step-1), skew: 0, wantOK: false},
+		{name: "outside skew", code: totpCode(rfc6238Secret, step-2), skew: 1, wantOK: false},
+		{name: "wrong code", code: "000000", skew: 1, wantOK: false},
+		{name: "8-digit code", code: "07081804", skew: 1, wantOK: false},
+		{name: "empty code", code: "", skew: 1, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := totpMatch(rfc6238Secret, tt.code, now, tt.skew)
+			if ok != tt.wantOK {
+				t.Fatalf("totpMatch() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && gotStep != tt.wantStep {
+				t.Errorf("totpMatch() step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
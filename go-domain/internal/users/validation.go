@@ -0,0 +1,70 @@
+package users
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Nishant1719/GO-FULLSTACK-PROJECT/tree/main/go-domain/internal/problem"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// usernamePattern allows letters, digits, '.', '_' and '-', starting with a letter or digit
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// reservedUsernames cannot be registered because they collide with
+// routes, system accounts or could be used to impersonate staff
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"api":           {},
+	"me":            {},
+	"null":          {},
+	"root":          {},
+	"support":       {},
+	"system":        {},
+	"undefined":     {},
+}
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators adds the users rules to gin's validator.
+// Safe to call more than once.
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			slog.Warn("Unexpected validator engine, custom user rules not registered")
+			return
+		}
+
+		v.RegisterTagNameFunc(problem.JSONFieldName)
+
+		if err := v.RegisterValidation("username", validateUsername); err != nil {
+			slog.Error("Failed to register username validator", "error", err)
+		}
+		if err := v.RegisterValidation("not_reserved", validateNotReserved); err != nil {
+			slog.Error("Failed to register not_reserved validator", "error", err)
+		}
+
+		problem.RegisterMessage("username", func(validator.FieldError) string {
+			return "may only contain letters, digits, '.', '_' and '-' and must start with a letter or digit"
+		})
+		problem.RegisterMessage("not_reserved", func(validator.FieldError) string {
+			return "is reserved and cannot be used"
+		})
+	})
+}
+
+// validateUsername checks the username character set
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+// validateNotReserved rejects reserved usernames, ignoring case
+func validateNotReserved(fl validator.FieldLevel) bool {
+	_, reserved := reservedUsernames[strings.ToLower(fl.Field().String())]
+	return !reserved
+}
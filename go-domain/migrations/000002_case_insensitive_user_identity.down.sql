@@ -0,0 +1,8 @@
+-- Revert usernames and emails to case-sensitive columns
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_email_length,
+    DROP CONSTRAINT IF EXISTS users_username_length;
+
+ALTER TABLE users
+    ALTER COLUMN username TYPE VARCHAR(255),
+    ALTER COLUMN email TYPE VARCHAR(255);
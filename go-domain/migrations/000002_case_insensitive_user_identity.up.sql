@@ -0,0 +1,42 @@
+-- Compare usernames and emails case-insensitively
+CREATE EXTENSION IF NOT EXISTS citext;
+
+-- Abort if existing rows would collide after normalization.
+-- Run scripts/check_user_collisions.sql to list them.
+DO $$
+DECLARE
+    username_collisions INTEGER;
+    email_collisions INTEGER;
+BEGIN
+    SELECT COUNT(*) INTO username_collisions FROM (
+        SELECT 1 FROM users
+        GROUP BY lower(normalize(btrim(username), NFKC))
+        HAVING COUNT(*) > 1
+    ) AS c;
+
+    SELECT COUNT(*) INTO email_collisions FROM (
+        SELECT 1 FROM users
+        GROUP BY lower(normalize(btrim(email), NFKC))
+        HAVING COUNT(*) > 1
+    ) AS c;
+
+    IF username_collisions > 0 OR email_collisions > 0 THEN
+        RAISE EXCEPTION 'found % username and % email collisions, see scripts/check_user_collisions.sql',
+            username_collisions, email_collisions;
+    END IF;
+END $$;
+
+-- Normalize existing values the same way the service does for new writes
+UPDATE users
+SET username = normalize(btrim(username), NFKC),
+    email = lower(normalize(btrim(email), NFKC));
+
+-- Switch to citext; the existing unique constraints now ignore case
+ALTER TABLE users
+    ALTER COLUMN username TYPE CITEXT,
+    ALTER COLUMN email TYPE CITEXT;
+
+-- citext has no length modifier, keep the previous 255 character limit
+ALTER TABLE users
+    ADD CONSTRAINT users_username_length CHECK (char_length(username) <= 255),
+    ADD CONSTRAINT users_email_length CHECK (char_length(email) <= 255);
@@ -0,0 +1,10 @@
+-- Restore table-wide uniqueness. Fails if a deleted account shares a
+-- username or email with another account; resolve those rows first.
+DROP INDEX IF EXISTS users_active_email_key;
+DROP INDEX IF EXISTS users_active_username_key;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_username_key UNIQUE (username),
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE users ALTER COLUMN is_active DROP NOT NULL;
@@ -0,0 +1,12 @@
+-- Only active users reserve a username or email. Deleted (is_active = false)
+-- rows are kept for history but release their identifiers, so the same
+-- username or email can be registered again as a brand new account.
+UPDATE users SET is_active = false WHERE is_active IS NULL;
+ALTER TABLE users ALTER COLUMN is_active SET NOT NULL;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_username_key,
+    DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX users_active_username_key ON users (username) WHERE is_active;
+CREATE UNIQUE INDEX users_active_email_key ON users (email) WHERE is_active;
@@ -0,0 +1,16 @@
+-- Collapse status back into is_active. Suspended users become inactive,
+-- which previously meant deleted, so they release their identifiers too.
+ALTER TABLE users ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT true;
+UPDATE users SET is_active = (status = 'active');
+
+DROP INDEX IF EXISTS idx_users_status;
+DROP INDEX IF EXISTS users_live_email_key;
+DROP INDEX IF EXISTS users_live_username_key;
+CREATE UNIQUE INDEX users_active_username_key ON users (username) WHERE is_active;
+CREATE UNIQUE INDEX users_active_email_key ON users (email) WHERE is_active;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_deleted_at_check,
+    DROP CONSTRAINT IF EXISTS users_status_check,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS status;
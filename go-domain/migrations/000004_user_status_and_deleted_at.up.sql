@@ -0,0 +1,25 @@
+-- Split is_active into an explicit lifecycle status:
+--   active    - normal account
+--   suspended - blocked by an admin, still owns its username and email
+--   deleted   - soft deleted, releases its username and email
+ALTER TABLE users
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Until now is_active = false always meant deleted
+UPDATE users SET status = 'deleted', deleted_at = updated_at WHERE NOT is_active;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'suspended', 'deleted')),
+    ADD CONSTRAINT users_deleted_at_check CHECK ((status = 'deleted') = (deleted_at IS NOT NULL));
+
+-- Suspended users keep their identifiers, only deleted users release them
+DROP INDEX IF EXISTS users_active_username_key;
+DROP INDEX IF EXISTS users_active_email_key;
+CREATE UNIQUE INDEX users_live_username_key ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_live_email_key ON users (email) WHERE deleted_at IS NULL;
+
+-- Create index on status for admin list filters
+CREATE INDEX idx_users_status ON users(status);
+
+ALTER TABLE users DROP COLUMN is_active;
@@ -0,0 +1,2 @@
+-- Track the last successful credential check
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE;
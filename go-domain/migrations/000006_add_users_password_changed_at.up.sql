@@ -0,0 +1,6 @@
+-- Track when the password last changed so the BFF can invalidate older sessions
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE;
+UPDATE users SET password_changed_at = created_at;
+ALTER TABLE users
+    ALTER COLUMN password_changed_at SET DEFAULT NOW(),
+    ALTER COLUMN password_changed_at SET NOT NULL;
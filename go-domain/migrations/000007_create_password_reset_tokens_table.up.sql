@@ -0,0 +1,13 @@
+-- Single-use password reset tokens. Only a SHA-256 hash of the token is
+-- stored; the plaintext is handed to the mailer once and never persisted.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create index for per-account rate limiting
+CREATE INDEX idx_password_reset_tokens_user_created ON password_reset_tokens(user_id, created_at DESC);
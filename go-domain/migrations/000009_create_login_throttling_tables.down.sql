@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_login_lockout_events_user_id;
+DROP TABLE IF EXISTS login_lockout_events;
+DROP TABLE IF EXISTS login_throttles;
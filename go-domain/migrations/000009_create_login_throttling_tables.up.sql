@@ -0,0 +1,27 @@
+-- Failed login counters shared by every API replica.
+-- subject_type is 'user' (subject = user id) or 'ip' (subject = client IP).
+CREATE TABLE IF NOT EXISTS login_throttles (
+    subject_type VARCHAR(8) NOT NULL CHECK (subject_type IN ('user', 'ip')),
+    subject VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (subject_type, subject)
+);
+
+-- Append-only audit trail of lockouts and admin unlocks
+CREATE TABLE IF NOT EXISTS login_lockout_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('locked', 'unlocked')),
+    subject_type VARCHAR(8) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    client_ip VARCHAR(64),
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create index for looking up a user's lockout history
+CREATE INDEX idx_login_lockout_events_user_id ON login_lockout_events(user_id, created_at DESC);
@@ -0,0 +1,45 @@
+-- Append-only record of changes to domain entities. Rows are written in the
+-- same transaction as the change they describe. actor_id has no foreign key
+-- so the trail survives the actor's account.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    actor_id UUID,
+    actor_tenant VARCHAR(255),
+    request_id VARCHAR(128),
+    client_ip VARCHAR(64),
+    -- {"field": {"before": ..., "after": ...}} with sensitive values redacted
+    changes JSONB NOT NULL DEFAULT '{}'
+);
+
+-- Create indexes for the audit API filters
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, id DESC);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, id DESC);
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at);
+
+-- Reject any attempt to rewrite history
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (name, description)
+VALUES ('audit:read', 'View the audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'audit:read' FROM roles WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
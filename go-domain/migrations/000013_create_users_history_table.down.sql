@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS users_history_update ON users;
+DROP TRIGGER IF EXISTS users_history_insert ON users;
+DROP FUNCTION IF EXISTS users_history_record();
+DROP INDEX IF EXISTS idx_users_history_valid_from;
+DROP INDEX IF EXISTS users_history_current_key;
+DROP TABLE IF EXISTS users_history;
@@ -0,0 +1,77 @@
+-- Temporal history of user records. Every insert or update of a tracked
+-- column closes the open revision (valid_to = NOW()) and opens a new one, so
+-- the state at time t is the row with valid_from <= t < valid_to.
+-- password_hash and last_login_at are not tracked. The counter is called
+-- revision so it cannot be confused with users.version, the optimistic
+-- concurrency counter sent as the ETag.
+CREATE TABLE IF NOT EXISTS users_history (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    username CITEXT NOT NULL,
+    email CITEXT NOT NULL,
+    first_name VARCHAR(255),
+    last_name VARCHAR(255),
+    status VARCHAR(16) NOT NULL,
+    email_verified_at TIMESTAMP WITH TIME ZONE,
+    password_changed_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, revision)
+);
+
+-- At most one open revision per user
+CREATE UNIQUE INDEX users_history_current_key ON users_history(user_id) WHERE valid_to IS NULL;
+
+-- Create index for point-in-time lookups
+CREATE INDEX idx_users_history_valid_from ON users_history(user_id, valid_from DESC);
+
+CREATE OR REPLACE FUNCTION users_history_record()
+RETURNS TRIGGER AS $$
+DECLARE
+    next_revision INTEGER;
+BEGIN
+    UPDATE users_history
+    SET valid_to = NOW()
+    WHERE user_id = NEW.id AND valid_to IS NULL;
+
+    SELECT COALESCE(MAX(revision), 0) + 1 INTO next_revision
+    FROM users_history
+    WHERE user_id = NEW.id;
+
+    INSERT INTO users_history (
+        user_id, revision, username, email, first_name, last_name, status,
+        email_verified_at, password_changed_at, deleted_at, created_at, valid_from
+    ) VALUES (
+        NEW.id, next_revision, NEW.username, NEW.email, NEW.first_name, NEW.last_name, NEW.status,
+        NEW.email_verified_at, NEW.password_changed_at, NEW.deleted_at, NEW.created_at, NOW()
+    );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_history_insert
+    AFTER INSERT ON users
+    FOR EACH ROW EXECUTE FUNCTION users_history_record();
+
+CREATE TRIGGER users_history_update
+    AFTER UPDATE ON users
+    FOR EACH ROW
+    WHEN ((OLD.username, OLD.email, OLD.first_name, OLD.last_name, OLD.status,
+           OLD.email_verified_at, OLD.password_changed_at, OLD.deleted_at)
+          IS DISTINCT FROM
+          (NEW.username, NEW.email, NEW.first_name, NEW.last_name, NEW.status,
+           NEW.email_verified_at, NEW.password_changed_at, NEW.deleted_at))
+    EXECUTE FUNCTION users_history_record();
+
+-- Seed revision 1 for existing users from their current state
+INSERT INTO users_history (
+    user_id, revision, username, email, first_name, last_name, status,
+    email_verified_at, password_changed_at, deleted_at, created_at, valid_from
+)
+SELECT id, 1, username, email, first_name, last_name, status,
+       email_verified_at, password_changed_at, deleted_at, created_at, COALESCE(updated_at, created_at)
+FROM users
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- Row version for optimistic concurrency. Every write that changes what the
+-- API returns for a user increments it; it is sent to clients as the ETag.
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
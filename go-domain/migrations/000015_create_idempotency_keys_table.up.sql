@@ -0,0 +1,17 @@
+-- Responses to POST requests sent with an Idempotency-Key header. A row is
+-- claimed before the handler runs (status_code NULL while in flight) and
+-- completed with the response, which is replayed for retries until expiry.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    caller VARCHAR(64) NOT NULL,  -- user id of the caller, empty for anonymous requests
+    key VARCHAR(255) NOT NULL,
+    fingerprint BYTEA NOT NULL,   -- SHA-256 of method, path and body
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (caller, key)
+);
+
+-- Create index for purging expired keys
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at DESC);
+DROP INDEX IF EXISTS idx_users_created_at_id;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,7 @@
+-- Keyset pagination compares (created_at, id), which never matches NULLs
+UPDATE users SET created_at = COALESCE(updated_at, NOW()) WHERE created_at IS NULL;
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+
+-- Create index for keyset pagination; replaces the created_at index from 000001
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users(created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_users_created_at;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_email_prefix;
+DROP INDEX IF EXISTS idx_users_username_prefix;
+DROP INDEX IF EXISTS idx_users_email_id;
+DROP INDEX IF EXISTS idx_users_username_id;
+DROP INDEX IF EXISTS idx_users_updated_at_id;
+ALTER TABLE users ALTER COLUMN updated_at DROP NOT NULL;
@@ -0,0 +1,12 @@
+-- Keyset pagination by updated_at compares (updated_at, id), which never matches NULLs
+UPDATE users SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE users ALTER COLUMN updated_at SET NOT NULL;
+
+-- Create indexes for the other sort orders of the user listing
+CREATE INDEX IF NOT EXISTS idx_users_updated_at_id ON users(updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_users_username_id ON users(username, id);
+CREATE INDEX IF NOT EXISTS idx_users_email_id ON users(email, id);
+
+-- Create indexes for the case-insensitive prefix filters
+CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users(lower(username::text) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_prefix ON users(lower(email::text) text_pattern_ops);
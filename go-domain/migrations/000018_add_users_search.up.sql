@@ -0,0 +1,20 @@
+-- Trigram matching for misspelled names and emails
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full-text document of a user. The simple configuration keeps names as
+-- written instead of stemming them as English words. Emails are also split
+-- on their punctuation so "doe" finds john.doe@example.com.
+ALTER TABLE users ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', username::text), 'A') ||
+    setweight(to_tsvector('simple', email::text || ' ' || regexp_replace(email::text, '[@._+-]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'B')
+) STORED;
+
+-- Create index for full-text search
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector);
+
+-- Create indexes for trigram similarity
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN ((username::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN ((email::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_first_name_trgm ON users USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_last_name_trgm ON users USING GIN (last_name gin_trgm_ops);
@@ -0,0 +1,27 @@
+-- Report users that would collide once usernames and emails are compared
+-- case-insensitively after trimming and Unicode NFKC normalization.
+-- Run before migration 000002, e.g.:
+--   docker exec -i go-domain-postgres psql -U postgres -d go_domain_db < scripts/check_user_collisions.sql
+-- Resolve every row listed here (rename or merge accounts) before migrating.
+
+SELECT 'username' AS field,
+       lower(normalize(btrim(username), NFKC)) AS normalized_value,
+       COUNT(*) AS accounts,
+       array_agg(id ORDER BY created_at) AS user_ids,
+       array_agg(username ORDER BY created_at) AS stored_values
+FROM users
+GROUP BY lower(normalize(btrim(username), NFKC))
+HAVING COUNT(*) > 1
+
+UNION ALL
+
+SELECT 'email' AS field,
+       lower(normalize(btrim(email), NFKC)) AS normalized_value,
+       COUNT(*) AS accounts,
+       array_agg(id ORDER BY created_at) AS user_ids,
+       array_agg(email ORDER BY created_at) AS stored_values
+FROM users
+GROUP BY lower(normalize(btrim(email), NFKC))
+HAVING COUNT(*) > 1
+
+ORDER BY field, normalized_value;
@@ -0,0 +1,10 @@
+-- Grant the built-in admin role to an existing user. Needed once to
+-- bootstrap role administration, since assigning roles requires roles:write.
+--   docker exec -i go-domain-postgres psql -U postgres -d go_domain_db -v username=jane < scripts/grant_admin.sql
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u, roles r
+WHERE u.username = :'username' AND u.deleted_at IS NULL AND r.name = 'admin'
+ON CONFLICT DO NOTHING
+RETURNING user_id, role_id;